@@ -5,23 +5,16 @@ go 1.20
 require (
 	github.com/go-redis/redis v6.15.9+incompatible
 	github.com/neazossa/common-util-go/cache/cache v1.0.0
-	github.com/neazossa/common-util-go/logger/logger v1.0.0
-	github.com/neazossa/common-util-go/monitor/monitor v1.0.0
+	github.com/neazossa/common-util-go/logger/logger v1.1.0
+	github.com/neazossa/common-util-go/monitor/monitor v1.1.0
 	github.com/pkg/errors v0.9.1
 )
 
 require (
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/google/go-cmp v0.5.8 // indirect
-	github.com/labstack/echo/v4 v4.9.0 // indirect
-	github.com/labstack/gommon v0.3.1 // indirect
-	github.com/mattn/go-colorable v0.1.11 // indirect
-	github.com/mattn/go-isatty v0.0.14 // indirect
 	github.com/onsi/ginkgo v1.16.5 // indirect
 	github.com/onsi/gomega v1.20.2 // indirect
-	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	github.com/valyala/fasttemplate v1.2.1 // indirect
-	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5 // indirect
 	golang.org/x/net v0.0.0-20220722155237-a158d28d115b // indirect
 	golang.org/x/sys v0.0.0-20220722155257-8c9f86f7a55f // indirect
 	golang.org/x/text v0.3.7 // indirect
@@ -17,9 +17,9 @@ import (
 
 type (
 	Option struct {
-		Host               string
-		Port               string
-		Password           string
+		Host               string `config:",required"`
+		Port               string `default:"6379"`
+		Password           string `config:",secret"`
 		DB                 int
 		PoolSize           int
 		MinIdleCons        int
@@ -304,10 +304,10 @@ func (r *Redis) Monitor(ctx context.Context, mntr monitor.Monitor, requestId str
 		client:             r.client,
 		logger:             r.logger,
 		maxDelPerOperation: r.maxDelPerOperation,
-		isMonitor:          true,
+		isMonitor:          mntr != nil,
 		monitor:            mntr,
 		context:            ctx,
-		isCaptureError:     captureError,
+		isCaptureError:     captureError && mntr != nil,
 		requestId:          requestId,
 	}
 }
@@ -348,6 +348,7 @@ func (r *Redis) startMonitor(action string, keys ...string) monitor.Transaction
 
 func (r *Redis) finishMonitor(transaction monitor.Transaction) {
 	transaction.Finish()
+	r.monitor.AddBreadcrumb(r.context, monitor.TransactionBreadcrumb(transaction, monitor.BreadcrumbQuery))
 }
 
 func (r *Redis) doMonitor(action string, keys ...string) func() {
@@ -362,7 +363,7 @@ func (r *Redis) doMonitor(action string, keys ...string) func() {
 
 func (r *Redis) captureError(err error) error {
 	if r.isCaptureError {
-		r.monitor.Capture(err)
+		r.monitor.WithContext(r.context).Capture(err)
 	}
 	return err
 }
@@ -0,0 +1,456 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	TagConfig  = "config"
+	TagDefault = "default"
+
+	// SuffixFile read the value of a variable from a file, e.g. REDIS_PASSWORD_FILE=/run/secrets/redis_password
+	SuffixFile = "_FILE"
+)
+
+var (
+	ErrInvalidTarget = errors.New("config: target must be a non nil pointer to a struct")
+	ErrRequired      = errors.New("required")
+	ErrUnsupported   = errors.New("unsupported type")
+
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type (
+	// Option configure Load
+	// - Prefix is prepended to every environment variable, e.g. APP give APP_REDIS_HOST
+	// - Files are YAML (.yaml, .yml) or JSON (.json) files merged in order, a later file override the earlier ones
+	// - LookupEnv replace os.LookupEnv, e.g. to load from a map in tests
+	Option struct {
+		Prefix    string
+		Files     []string
+		LookupEnv func(key string) (string, bool)
+	}
+
+	tag struct {
+		name     string
+		skip     bool
+		required bool
+		secret   bool
+	}
+
+	loader struct {
+		lookupEnv func(key string) (string, bool)
+		errs      []error
+	}
+)
+
+// Load fill target, a pointer to a struct such as goredis.Option or a struct grouping the options of a service.
+// Every exported field is looked up, the first found win :
+// 1. the file named by the environment variable <NAME>_FILE, for the secrets mounted as files
+// 2. the environment variable <NAME>, the snake case of the field path, e.g. REDIS_DIAL_TIMEOUT
+// 3. the key of the files matching the field name, case and separator insensitive, e.g. redis.dial_timeout
+// 4. the value already in target, then the default tag when it is zero and no source above set it
+//
+// The fields are tagged with `config:"name,required,secret"` and `default:"value"`, name replace the field name and
+// "-" skip the field. The missing required fields and the invalid values are all returned at once.
+func Load(target interface{}, option Option) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+
+	file := make(map[string]interface{})
+	for _, name := range option.Files {
+		values, err := readFile(name)
+		if err != nil {
+			return err
+		}
+		merge(file, values)
+	}
+
+	l := &loader{lookupEnv: option.LookupEnv}
+	if l.lookupEnv == nil {
+		l.lookupEnv = os.LookupEnv
+	}
+
+	l.load(v.Elem(), strings.ToUpper(option.Prefix), "", file)
+	return errors.Join(l.errs...)
+}
+
+// load fill the fields of v and tell whether a value was found in the environment or the files
+func (l *loader) load(v reflect.Value, env, path string, file map[string]interface{}) bool {
+	found := false
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		t := parseTag(field)
+		if t.skip {
+			continue
+		}
+
+		value := v.Field(i)
+
+		// - the fields of an embedded struct are flattened into its parent
+		if field.Anonymous && nested(field.Type) {
+			found = l.load(value, env, path, file) || found
+			continue
+		}
+
+		name := t.name
+		if name == "" {
+			name = snake(field.Name)
+		}
+		fieldEnv := join(env, strings.ToUpper(name), "_")
+		fieldPath := join(path, field.Name, ".")
+		raw, inFile := lookupFile(file, name, field.Name)
+
+		switch {
+		case nested(field.Type):
+			section, _ := raw.(map[string]interface{})
+			found = l.load(value, fieldEnv, fieldPath, section) || found
+			continue
+		case field.Type.Kind() == reflect.Ptr && nested(field.Type.Elem()):
+			found = l.loadPtr(value, fieldEnv, fieldPath, raw) || found
+			continue
+		case !supported(field.Type):
+			continue
+		}
+
+		var (
+			err error
+			set = true
+		)
+		if secretFile, ok := l.env(fieldEnv + SuffixFile); ok {
+			err = setFile(value, secretFile)
+		} else if s, ok := l.env(fieldEnv); ok {
+			err = setString(value, s)
+		} else if inFile && raw != nil {
+			err = setRaw(value, raw)
+		} else {
+			set = false
+		}
+		found = set || found
+
+		// - an explicit 0, false or empty value is kept, the default is only for the fields no source set
+		if !set && value.IsZero() {
+			if def, ok := field.Tag.Lookup(TagDefault); ok {
+				err = setString(value, def)
+			}
+		}
+
+		switch {
+		case err != nil:
+			if t.secret && !errors.As(err, new(*fs.PathError)) {
+				// - the errors of strconv quote the value
+				err = errors.New("invalid value")
+			}
+			l.errs = append(l.errs, fmt.Errorf("config: %s (%s): %w", fieldPath, fieldEnv, err))
+		case t.required && value.IsZero():
+			l.errs = append(l.errs, fmt.Errorf("config: %s (%s): %w", fieldPath, fieldEnv, ErrRequired))
+		}
+	}
+
+	return found
+}
+
+// loadPtr fill an optional section such as logger.Option.Async, it is allocated only when one of its values is found
+func (l *loader) loadPtr(value reflect.Value, env, path string, raw interface{}) bool {
+	elem := reflect.New(value.Type().Elem())
+	if !value.IsNil() {
+		elem.Elem().Set(value.Elem())
+	}
+
+	errs := len(l.errs)
+	section, _ := raw.(map[string]interface{})
+	if !l.load(elem.Elem(), env, path, section) && value.IsNil() {
+		// - the required fields of an absent section are not missing
+		l.errs = l.errs[:errs]
+		return false
+	}
+
+	value.Set(elem)
+	return true
+}
+
+// env return the variable when it is set and not empty
+func (l *loader) env(key string) (string, bool) {
+	s, ok := l.lookupEnv(key)
+	return s, ok && s != ""
+}
+
+func parseTag(field reflect.StructField) tag {
+	s, ok := field.Tag.Lookup(TagConfig)
+	if !ok {
+		return tag{}
+	}
+	if s == "-" {
+		return tag{skip: true}
+	}
+
+	parts := strings.Split(s, ",")
+	t := tag{name: strings.TrimSpace(parts[0])}
+	for _, part := range parts[1:] {
+		switch strings.TrimSpace(part) {
+		case "required":
+			t.required = true
+		case "secret":
+			t.secret = true
+		}
+	}
+	return t
+}
+
+// nested tell whether t is a section whose fields are loaded one by one
+func nested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func supported(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return supported(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && supported(t.Elem())
+	default:
+		return false
+	}
+}
+
+// setFile set value from the content of the file, without the trailing new line most editors add
+func setFile(value reflect.Value, name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return setString(value, strings.TrimRight(string(data), "\r\n"))
+}
+
+// setString parse s, the slices are comma separated and the maps are comma separated key=value pairs
+func setString(value reflect.Value, s string) error {
+	if u, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			value.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		parts := split(s)
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setString(slice.Index(i), part); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(value.Type())
+		for _, pair := range split(s) {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("%q is not a key=value pair", pair)
+			}
+			if err := setMapIndex(m, strings.TrimSpace(k), v); err != nil {
+				return err
+			}
+		}
+		value.Set(m)
+	default:
+		return ErrUnsupported
+	}
+	return nil
+}
+
+// setRaw set value from a value decoded from a file
+func setRaw(value reflect.Value, raw interface{}) error {
+	textual := reflect.PointerTo(value.Type()).Implements(textUnmarshalerType)
+
+	switch r := raw.(type) {
+	case string:
+		return setString(value, r)
+	case float64:
+		// - json decode every number as float64, 'f' keep the integers parsable
+		return setString(value, strconv.FormatFloat(r, 'f', -1, 64))
+	case []interface{}:
+		if textual || value.Kind() != reflect.Slice {
+			return fmt.Errorf("list given to %s", value.Type())
+		}
+		slice := reflect.MakeSlice(value.Type(), len(r), len(r))
+		for i, item := range r {
+			if err := setRaw(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	case map[string]interface{}:
+		if textual || value.Kind() != reflect.Map {
+			return fmt.Errorf("map given to %s", value.Type())
+		}
+		m := reflect.MakeMap(value.Type())
+		for k, item := range r {
+			if err := setMapIndex(m, k, item); err != nil {
+				return err
+			}
+		}
+		value.Set(m)
+		return nil
+	default:
+		return setString(value, fmt.Sprint(r))
+	}
+}
+
+func setMapIndex(m reflect.Value, key string, raw interface{}) error {
+	item := reflect.New(m.Type().Elem()).Elem()
+	if err := setRaw(item, raw); err != nil {
+		return err
+	}
+	m.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), item)
+	return nil
+}
+
+func readFile(name string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("config: %s: unknown file extension, expected .yaml, .yml or .json", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: %s: %w", name, err)
+	}
+	return values, nil
+}
+
+// merge copy src into dst, the sections are merged key by key
+func merge(dst, src map[string]interface{}) {
+	for k, v := range src {
+		section, ok := v.(map[string]interface{})
+		if current, isMap := dst[k].(map[string]interface{}); ok && isMap {
+			merge(current, section)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// lookupFile find the key matching one of names, ignoring the case, the underscores and the dashes
+func lookupFile(file map[string]interface{}, names ...string) (interface{}, bool) {
+	for k, v := range file {
+		key := normalize(k)
+		for _, name := range names {
+			if key == normalize(name) {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+}
+
+// snake turn a field name into an environment variable name, e.g. DBName give DB_NAME and HTTPSProxy give HTTPS_PROXY
+func snake(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func split(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func join(prefix, name, sep string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + sep + name
+}
@@ -0,0 +1,29 @@
+package echohealth
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/neazossa/common-util-go/health/health"
+)
+
+// Register serve the liveness and readiness reports on health.PathLiveness and health.PathReadiness
+func Register(e *echo.Echo, h *health.Health) {
+	e.GET(health.PathLiveness, Liveness(h))
+	e.GET(health.PathReadiness, Readiness(h))
+}
+
+// Liveness serve the liveness report as JSON
+func Liveness(h *health.Health) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report := h.Liveness(c.Request().Context())
+		return c.JSON(report.StatusCode(), report)
+	}
+}
+
+// Readiness serve the readiness report as JSON, with HTTP 503 when the service is DOWN
+func Readiness(h *health.Health) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report := h.Readiness(c.Request().Context())
+		c.Response().Header().Set("Cache-Control", "no-store")
+		return c.JSON(report.StatusCode(), report)
+	}
+}
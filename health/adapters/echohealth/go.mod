@@ -0,0 +1,24 @@
+module github.com/neazossa/common-util-go/health/adapters/echohealth
+
+go 1.20
+
+require (
+	github.com/labstack/echo/v4 v4.10.0
+	github.com/neazossa/common-util-go/health/health v1.0.0
+)
+
+require (
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/labstack/gommon v0.4.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.17 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/crypto v0.7.0 // indirect
+	golang.org/x/net v0.8.0 // indirect
+	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/text v0.8.0 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+	google.golang.org/grpc v1.49.0 // indirect
+	google.golang.org/protobuf v1.29.1 // indirect
+)
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// grpcServer implement the standard grpc health protocol, the empty service is the readiness of the whole
+	// server and a registered check name is the status of that check
+	grpcServer struct {
+		healthpb.UnimplementedHealthServer
+		health *Health
+	}
+)
+
+// RegisterGRPC serve the grpc health protocol on server, so grpc_health_probe and load balancers can probe it
+func (h *Health) RegisterGRPC(server *grpc.Server) {
+	healthpb.RegisterHealthServer(server, h.GRPCServer())
+}
+
+func (h *Health) GRPCServer() healthpb.HealthServer {
+	return &grpcServer{health: h}
+}
+
+func (s *grpcServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	servingStatus, ok := s.status(ctx, req.GetService())
+	if !ok {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+// Watch send the status of the service, then every change seen when probing each cache TTL
+func (s *grpcServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	var (
+		last   healthpb.HealthCheckResponse_ServingStatus = -1
+		ticker                                            = time.NewTicker(s.health.option.CacheTTL)
+	)
+	defer ticker.Stop()
+
+	for {
+		servingStatus, ok := s.status(stream.Context(), req.GetService())
+		if !ok {
+			servingStatus = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+
+		if servingStatus != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+			last = servingStatus
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *grpcServer) status(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	if service == "" {
+		return servingStatus(s.health.Readiness(ctx).Status), true
+	}
+
+	result, ok := s.health.Check(ctx, service)
+	if !ok {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, false
+	}
+	return servingStatus(result.Status), true
+}
+
+func servingStatus(status Status) healthpb.HealthCheckResponse_ServingStatus {
+	if status == StatusDown {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
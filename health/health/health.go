@@ -0,0 +1,284 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp       Status = "UP"
+	StatusDegraded Status = "DEGRADED" // a non critical check failed, the service still serve
+	StatusDown     Status = "DOWN"
+
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTTL = 5 * time.Second
+)
+
+var (
+	ErrNotReady = errors.New("service is not ready")
+)
+
+type (
+	Status string
+
+	// Checker tell whether a dependency is usable, such as mongo.Mongo.Ping
+	Checker interface {
+		Check(ctx context.Context) error
+	}
+
+	// CheckerFunc adapt a func to Checker, e.g. health.CheckerFunc(mongo.Ping)
+	CheckerFunc func(ctx context.Context) error
+
+	// Option configure NewHealth
+	// - Timeout of a check when its CheckOption has none, default 2 seconds
+	// - CacheTTL keep the result of a check, so frequent probes do not flood the dependencies, default 5 seconds
+	Option struct {
+		Timeout  time.Duration
+		CacheTTL time.Duration
+	}
+
+	// CheckOption configure a registered check, a failing Critical check make the service DOWN, DEGRADED otherwise
+	CheckOption struct {
+		Timeout  time.Duration
+		Critical bool
+	}
+
+	// Health aggregate the checks of the modules into liveness and readiness
+	Health struct {
+		option Option
+		mutex  sync.RWMutex
+		checks map[string]*check
+		ready  bool
+	}
+
+	check struct {
+		name    string
+		checker Checker
+		option  CheckOption
+		mutex   sync.Mutex // one probe at a time, the others wait for its result
+		result  CheckResult
+	}
+
+	// Report is the JSON status report served by the handlers
+	Report struct {
+		Status    Status                 `json:"status"`
+		Checks    map[string]CheckResult `json:"checks,omitempty"`
+		Timestamp time.Time              `json:"timestamp"`
+	}
+
+	CheckResult struct {
+		Status    Status    `json:"status"`
+		Critical  bool      `json:"critical"`
+		Error     string    `json:"error,omitempty"`
+		Duration  string    `json:"duration"`
+		CheckedAt time.Time `json:"checkedAt"`
+	}
+)
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// PingChecker adapt a ping without context such as cache.Cache.Ping or sql.ORM.Ping, the check stop waiting
+// for it on timeout
+func PingChecker(ping func() error) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() {
+			done <- ping()
+		}()
+
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// NewHealth create the aggregator, the service is ready until SetReady(false)
+func NewHealth(option Option) *Health {
+	if option.Timeout <= 0 {
+		option.Timeout = DefaultTimeout
+	}
+	if option.CacheTTL <= 0 {
+		option.CacheTTL = DefaultCacheTTL
+	}
+
+	return &Health{
+		option: option,
+		checks: make(map[string]*check),
+		ready:  true,
+	}
+}
+
+// Register add a check under name, registering the same name again replace it
+func (h *Health) Register(name string, checker Checker, option CheckOption) {
+	if option.Timeout <= 0 {
+		option.Timeout = h.option.Timeout
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.checks[name] = &check{
+		name:    name,
+		checker: checker,
+		option:  option,
+	}
+}
+
+// SetReady mark the service ready or not, a service shutting down stop being ready so no new traffic is routed to it
+func (h *Health) SetReady(ready bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.ready = ready
+}
+
+func (h *Health) isReady() bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.ready
+}
+
+// Liveness tell the process is running, the dependencies are not checked since restarting would not fix them
+func (h *Health) Liveness(ctx context.Context) Report {
+	return Report{
+		Status:    StatusUp,
+		Timestamp: time.Now(),
+	}
+}
+
+// Readiness run every check in parallel, using the cached result of the checks run during the cache TTL
+func (h *Health) Readiness(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusUp,
+		Checks:    make(map[string]CheckResult),
+		Timestamp: time.Now(),
+	}
+
+	if !h.isReady() {
+		report.Status = StatusDown
+		report.Checks["ready"] = CheckResult{
+			Status:    StatusDown,
+			Critical:  true,
+			Error:     ErrNotReady.Error(),
+			CheckedAt: report.Timestamp,
+		}
+		return report
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		checks  = h.list()
+		results = make(map[string]CheckResult, len(checks))
+	)
+
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c *check) {
+			defer wg.Done()
+			result := c.run(ctx, h.option.CacheTTL)
+
+			mutex.Lock()
+			results[c.name] = result
+			mutex.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	for name, result := range results {
+		report.Checks[name] = result
+		report.Status = worst(report.Status, result)
+	}
+	return report
+}
+
+// Check run the check registered under name, used by the grpc health protocol to check a single service
+func (h *Health) Check(ctx context.Context, name string) (CheckResult, bool) {
+	h.mutex.RLock()
+	c, ok := h.checks[name]
+	h.mutex.RUnlock()
+
+	if !ok {
+		return CheckResult{}, false
+	}
+	return c.run(ctx, h.option.CacheTTL), true
+}
+
+// list return the checks sorted by name
+func (h *Health) list() []*check {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	checks := make([]*check, 0, len(h.checks))
+	for _, c := range h.checks {
+		checks = append(checks, c)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].name < checks[j].name
+	})
+	return checks
+}
+
+// run return the cached result when it is younger than ttl, probe the dependency otherwise.
+// A failure caused by the caller, such as a probe cancelled by its client, is returned without being cached
+func (c *check) run(ctx context.Context, ttl time.Duration) CheckResult {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.result.CheckedAt.IsZero() && time.Since(c.result.CheckedAt) < ttl {
+		return c.result
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, c.option.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.safeCheck(checkCtx)
+
+	result := CheckResult{
+		Status:    StatusUp,
+		Critical:  c.option.Critical,
+		Duration:  time.Since(start).String(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+
+		if ctx.Err() != nil {
+			return result
+		}
+	}
+
+	c.result = result
+	return result
+}
+
+// safeCheck report a panic of the checker as failure, a probe must never crash the service
+func (c *check) safeCheck(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("check panic: %v", r)
+		}
+	}()
+	return c.checker.Check(ctx)
+}
+
+// worst return the status of the report once result is counted
+func worst(status Status, result CheckResult) Status {
+	if result.Status == StatusUp || status == StatusDown {
+		return status
+	}
+	if result.Critical {
+		return StatusDown
+	}
+	return StatusDegraded
+}
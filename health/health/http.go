@@ -0,0 +1,46 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	PathLiveness  = "/health/live"
+	PathReadiness = "/health/ready"
+)
+
+// LivenessHandler serve the liveness report as JSON
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Liveness(r.Context()))
+	})
+}
+
+// ReadinessHandler serve the readiness report as JSON, with HTTP 503 when the service is DOWN
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Readiness(r.Context()))
+	})
+}
+
+// RegisterHandlers serve the liveness and readiness handlers on PathLiveness and PathReadiness of mux
+func (h *Health) RegisterHandlers(mux *http.ServeMux) {
+	mux.Handle(PathLiveness, h.LivenessHandler())
+	mux.Handle(PathReadiness, h.ReadinessHandler())
+}
+
+// StatusCode return HTTP 503 for DOWN, a DEGRADED service still take traffic
+func (r Report) StatusCode() int {
+	if r.Status == StatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(report.StatusCode())
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,40 @@
+package resty
+
+import (
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/neazossa/common-util-go/metrics/metrics"
+)
+
+// observe record the request into the http_client metrics, the phases come from the resty trace info
+func observe(m *metrics.HTTPClientMetrics, method, host, route string, response *resty.Response, err error) {
+	var (
+		status  = 0
+		latency time.Duration
+	)
+
+	if response != nil {
+		if code := response.StatusCode(); err == nil && code > 0 {
+			status = code
+		}
+		latency = response.Time()
+	}
+
+	m.Observe(method, host, route, status, latency)
+
+	if status == 0 || response.Request == nil {
+		return
+	}
+
+	trace := response.Request.TraceInfo()
+	for phase, duration := range map[string]time.Duration{
+		metrics.PhaseDNS:     trace.DNSLookup,
+		metrics.PhaseConnect: trace.TCPConnTime,
+		metrics.PhaseTLS:     trace.TLSHandshake,
+		metrics.PhaseTTFB:    trace.ServerTime,
+	} {
+		// - reused connection skip dns, connect and tls
+		m.ObservePhase(host, route, phase, duration)
+	}
+}
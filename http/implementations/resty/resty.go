@@ -9,7 +9,9 @@ import (
 
 	httpRest "github.com/neazossa/common-util-go/http/http"
 	"github.com/neazossa/common-util-go/logger/logger"
+	"github.com/neazossa/common-util-go/metrics/metrics"
 	"github.com/neazossa/common-util-go/monitor/monitor"
+	"github.com/neazossa/common-util-go/shared/requestctx"
 	"github.com/go-resty/resty/v2"
 )
 
@@ -22,6 +24,16 @@ type (
 		context      context.Context
 		requestId    string
 		captureError bool
+		metrics      *metrics.HTTPClientMetrics
+		routes       []string
+	}
+
+	// Option configure NewHttpRestyClientWithOption
+	// - Metrics record the http_client metrics per host and route when set, with MetricsOption
+	Option struct {
+		Metrics       metrics.Metrics
+		MetricsOption metrics.ClientOption
+		Routes        []string // route templates such as /users/{id}, used to name transactions and metrics
 	}
 
 	RequestLog struct {
@@ -39,8 +51,17 @@ type (
 )
 
 func NewHttpRestyClient(ctx context.Context, logger logger.Logger) httpRest.Restful {
+	return NewHttpRestyClientWithOption(ctx, logger, Option{})
+}
+
+func NewHttpRestyClientWithOption(ctx context.Context, logger logger.Logger, option Option) httpRest.Restful {
 	client := resty.New()
 
+	var clientMetrics *metrics.HTTPClientMetrics
+	if option.Metrics != nil {
+		clientMetrics = metrics.NewHTTPClientMetrics(option.Metrics, option.MetricsOption)
+	}
+
 	return &Client{
 		logger: logger,
 		client: client.
@@ -48,18 +69,14 @@ func NewHttpRestyClient(ctx context.Context, logger logger.Logger) httpRest.Rest
 			OnBeforeRequest(preRequest(ctx, logger)).
 			OnAfterResponse(postResponse(ctx, logger)).
 			OnError(onError(ctx, logger)),
+		metrics: clientMetrics,
+		routes:  option.Routes,
 	}
 }
 
 func preRequest(ctx context.Context, logger logger.Logger) resty.RequestMiddleware {
 	return func(restyClient *resty.Client, r *resty.Request) error {
-		var (
-			requestID      = ""
-			requestIDValue = ctx.Value("request_id")
-		)
-		if requestIDValue != nil {
-			requestID = requestIDValue.(string)
-		}
+		requestID, _ := requestctx.RequestIDFrom(ctx)
 
 		var requestLog = RequestLog{
 			URL:        r.URL,
@@ -82,10 +99,7 @@ func preRequest(ctx context.Context, logger logger.Logger) resty.RequestMiddlewa
 
 func postResponse(ctx context.Context, logger logger.Logger) resty.ResponseMiddleware {
 	return func(restyClient *resty.Client, r *resty.Response) error {
-		var (
-			requestID      = ""
-			requestIDValue = ctx.Value("request_id")
-		)
+		requestID, _ := requestctx.RequestIDFrom(ctx)
 		request := r.Request
 		var requestLog = RequestLog{
 			URL:          request.URL,
@@ -99,9 +113,6 @@ func postResponse(ctx context.Context, logger logger.Logger) resty.ResponseMiddl
 			Body:         request.Body,
 			ResponseBody: string(r.Body()),
 		}
-		if requestIDValue != nil {
-			requestID = requestIDValue.(string)
-		}
 		logger.WithFields(map[string]interface{}{
 			"request": requestLog,
 		}).Info("postResponse Resty", requestID)
@@ -111,10 +122,7 @@ func postResponse(ctx context.Context, logger logger.Logger) resty.ResponseMiddl
 
 func onError(ctx context.Context, logger logger.Logger) resty.ErrorHook {
 	return func(request *resty.Request, err error) {
-		var (
-			requestID      = ""
-			requestIDValue = ctx.Value("request_id")
-		)
+		requestID, _ := requestctx.RequestIDFrom(ctx)
 		var requestLog = RequestLog{
 			URL:          request.URL,
 			Method:       request.Method,
@@ -127,9 +135,6 @@ func onError(ctx context.Context, logger logger.Logger) resty.ErrorHook {
 			Body:         request.Body,
 			ResponseBody: err.Error(),
 		}
-		if requestIDValue != nil {
-			requestID = requestIDValue.(string)
-		}
 		logger.WithFields(map[string]interface{}{
 			"request": requestLog,
 		}).Error("onError Resty", requestID)
@@ -137,7 +142,10 @@ func onError(ctx context.Context, logger logger.Logger) resty.ErrorHook {
 }
 
 func (c *Client) GET(path string, header map[string]string, param *map[string]interface{}) (*httpRest.BaseResponse, error) {
-	tr := c.startMonitor("GET", path)
+	var (
+		rawPath = path
+		tr      = c.startMonitor("GET", path)
+	)
 
 	if param != nil && len(*param) > 0 {
 		template := "%s=%s"
@@ -153,54 +161,54 @@ func (c *Client) GET(path string, header map[string]string, param *map[string]in
 		}
 	}
 
-	request := c.client.R().SetHeaders(header)
+	request := c.newRequest(header, tr)
 	response, err := request.Get(path)
-	defer c.finishMonitor(tr, response, err)
+	defer c.finishMonitor(tr, "GET", rawPath, response, err)
 	return convertToBaseResponse(response), err
 }
 
 func (c *Client) POST(path string, header map[string]string, body interface{}) (*httpRest.BaseResponse, error) {
 	tr := c.startMonitor("POST", path)
 
-	request := c.client.R().SetHeaders(header).SetBody(body)
+	request := c.newRequest(header, tr).SetBody(body)
 	response, err := request.Post(path)
-	defer c.finishMonitor(tr, response, err)
+	defer c.finishMonitor(tr, "POST", path, response, err)
 	return convertToBaseResponse(response), err
 }
 
 func (c *Client) POSTForm(path string, header map[string]string, body map[string]string) (*httpRest.BaseResponse, error) {
 	tr := c.startMonitor("POST", path)
 
-	request := c.client.R().SetHeaders(header).SetFormData(body)
+	request := c.newRequest(header, tr).SetFormData(body)
 	response, err := request.Post(path)
-	defer c.finishMonitor(tr, response, err)
+	defer c.finishMonitor(tr, "POST", path, response, err)
 	return convertToBaseResponse(response), err
 }
 
 func (c *Client) PUT(path string, header map[string]string, body interface{}) (*httpRest.BaseResponse, error) {
 	tr := c.startMonitor("PUT", path)
 
-	request := c.client.R().SetHeaders(header).SetBody(body)
+	request := c.newRequest(header, tr).SetBody(body)
 	response, err := request.Put(path)
-	defer c.finishMonitor(tr, response, err)
+	defer c.finishMonitor(tr, "PUT", path, response, err)
 	return convertToBaseResponse(response), err
 }
 
 func (c *Client) PATCH(path string, header map[string]string, body interface{}) (*httpRest.BaseResponse, error) {
 	tr := c.startMonitor("PATCH", path)
 
-	request := c.client.R().SetHeaders(header).SetBody(body)
+	request := c.newRequest(header, tr).SetBody(body)
 	response, err := request.Patch(path)
-	defer c.finishMonitor(tr, response, err)
+	defer c.finishMonitor(tr, "PATCH", path, response, err)
 	return convertToBaseResponse(response), err
 }
 
 func (c *Client) DELETE(path string, header map[string]string, body interface{}) (*httpRest.BaseResponse, error) {
 	tr := c.startMonitor("DELETE", path)
-	request := c.client.R().SetHeaders(header).SetBody(body)
+	request := c.newRequest(header, tr).SetBody(body)
 
 	response, err := request.Delete(path)
-	defer c.finishMonitor(tr, response, err)
+	defer c.finishMonitor(tr, "DELETE", path, response, err)
 	return convertToBaseResponse(response), err
 }
 
@@ -210,11 +218,13 @@ func (c *Client) SetBasicAuth(username, password string) httpRest.Restful {
 	return &Client{
 		logger:       c.logger,
 		client:       c.client,
-		isMonitor:    true,
+		isMonitor:    c.isMonitor,
 		monitor:      c.monitor,
 		context:      c.context,
 		requestId:    c.requestId,
 		captureError: c.captureError,
+		metrics:      c.metrics,
+		routes:       c.routes,
 	}
 }
 
@@ -222,17 +232,33 @@ func (c *Client) Monitor(ctx context.Context, mntr monitor.Monitor, requestId st
 	return &Client{
 		logger:       c.logger,
 		client:       c.client,
-		isMonitor:    true,
+		isMonitor:    mntr != nil,
 		monitor:      mntr,
 		context:      ctx,
 		requestId:    requestId,
 		captureError: captureError,
+		metrics:      c.metrics,
+		routes:       c.routes,
 	}
 }
 
+func (c *Client) newRequest(header map[string]string, tr monitor.Transaction) *resty.Request {
+	request := c.client.R().SetHeaders(header)
+	if c.metrics != nil {
+		request.EnableTrace()
+	}
+
+	// - send the trace context so the called service continue the same trace
+	if c.isMonitor && tr != nil {
+		c.monitor.Inject(tr.CreateNewTransactionContext(c.context), monitor.HeaderCarrier(request.Header))
+	}
+	return request
+}
+
 func (c *Client) startMonitor(method, url string) monitor.Transaction {
 	if c.isMonitor {
-		name := fmt.Sprintf("%s %s", method, url)
+		host, route := routeTemplate(url, c.routes)
+		name := fmt.Sprintf("%s %s", method, route)
 		return c.monitor.NewTransactionFromContext(c.context, monitor.Tick{
 			Operation:       "http",
 			TransactionName: name,
@@ -240,18 +266,32 @@ func (c *Client) startMonitor(method, url string) monitor.Transaction {
 				{"requestId", c.requestId},
 				{"action", name},
 				{"method", method},
+				{"host", host},
+				{"route", route},
 			},
 		})
 	}
 	return nil
 }
 
-func (c *Client) finishMonitor(transaction monitor.Transaction, response *resty.Response, err error) {
-	var (
-		statusCode = response.StatusCode()
-		status     = response.Status()
-	)
+func (c *Client) finishMonitor(transaction monitor.Transaction, method, url string, response *resty.Response, err error) {
+	if c.metrics != nil {
+		host, route := routeTemplate(url, c.routes)
+		observe(c.metrics, method, host, route, response, err)
+	}
+
 	if c.isMonitor {
+		var (
+			statusCode = 0
+			status     = ""
+		)
+
+		// - response is nil when the request failed before being sent
+		if response != nil {
+			statusCode = response.StatusCode()
+			status = response.Status()
+		}
+
 		if statusCode == 0 {
 			statusCode = 500
 			status = "InternalError"
@@ -261,8 +301,16 @@ func (c *Client) finishMonitor(transaction monitor.Transaction, response *resty.
 			{"code", fmt.Sprintf("%d", statusCode)},
 			{"status", status},
 		})
+
+		breadcrumb := monitor.TransactionBreadcrumb(transaction, monitor.BreadcrumbHTTP)
+		breadcrumb.Data["status_code"] = statusCode
+		if statusCode >= 500 {
+			breadcrumb.Level = monitor.LevelError
+		}
+		c.monitor.AddBreadcrumb(c.context, breadcrumb)
+
 		if c.captureError {
-			c.monitor.Capture(err)
+			c.monitor.WithContext(c.context).Capture(err)
 		}
 	}
 }
@@ -0,0 +1,114 @@
+package resty
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+const (
+	RouteIDPlaceholder = "{id}"
+)
+
+// routeTemplate split the requested url into host and route template.
+// The first matching template from routes is used, otherwise the path segments that look like identifiers
+// (numbers, uuid, hashes) are replaced by RouteIDPlaceholder to keep the cardinality low.
+func routeTemplate(rawUrl string, routes []string) (string, string) {
+	var (
+		host = ""
+		path = rawUrl
+	)
+
+	if u, err := url.Parse(rawUrl); err == nil {
+		host = u.Host
+		path = u.Path
+	} else if idx := strings.IndexAny(path, "?#"); idx >= 0 {
+		path = path[:idx]
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	for _, route := range routes {
+		if matchRoute(route, path) {
+			return host, route
+		}
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isIdentifier(segment) {
+			segments[i] = RouteIDPlaceholder
+		}
+	}
+	return host, strings.Join(segments, "/")
+}
+
+// matchRoute check path against route template.
+// Segment `{name}` or `:name` match any single segment and `*` match the rest of the path.
+func matchRoute(route, path string) bool {
+	var (
+		routeSegments = strings.Split(strings.Trim(route, "/"), "/")
+		pathSegments  = strings.Split(strings.Trim(path, "/"), "/")
+	)
+
+	for i, segment := range routeSegments {
+		if segment == "*" {
+			return true
+		}
+
+		if i >= len(pathSegments) {
+			return false
+		}
+
+		if isParam(segment) {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+
+	return len(routeSegments) == len(pathSegments)
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, ":") ||
+		(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"))
+}
+
+func isIdentifier(segment string) bool {
+	if segment == "" {
+		return false
+	}
+
+	var (
+		digits  = 0
+		letters = 0
+	)
+
+	for _, r := range segment {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case unicode.IsLetter(r):
+			letters++
+		case r == '-' || r == '_':
+		default:
+			return false
+		}
+	}
+
+	// - plain number such as /users/123
+	if letters == 0 && digits > 0 {
+		return true
+	}
+
+	// - uuid, object id or any long token mixing letters and digits
+	return digits > 0 && len(segment) >= 16
+}
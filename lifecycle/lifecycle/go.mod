@@ -0,0 +1,19 @@
+module github.com/neazossa/common-util-go/lifecycle/lifecycle
+
+go 1.20
+
+require (
+	github.com/neazossa/common-util-go/logger/logger v1.1.0
+	github.com/neazossa/common-util-go/monitor/monitor v1.1.0
+	google.golang.org/grpc v1.49.0
+)
+
+require (
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	golang.org/x/net v0.0.0-20211015210444-4f30a5c0130f // indirect
+	golang.org/x/sys v0.0.0-20211103235746-7861aae1554b // indirect
+	golang.org/x/text v0.3.7 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+	google.golang.org/protobuf v1.27.1 // indirect
+)
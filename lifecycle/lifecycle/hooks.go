@@ -0,0 +1,125 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/neazossa/common-util-go/monitor/monitor"
+	"google.golang.org/grpc"
+)
+
+var (
+	ErrFlushTimeout = errors.New("monitor flush timed out")
+)
+
+// Background run f in its own goroutine once started, with a context canceled when its phase is stopped.
+// Stop wait for f to return, so the message being handled is finished, e.g. kafka ReadMessages in PhaseConsumer.
+// When f fail before the shutdown, the error is logged and the shutdown is triggered.
+func (l *Lifecycle) Background(name string, phase Phase, f func(ctx context.Context) error) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan error, 1)
+	)
+
+	l.Append(Hook{
+		Name:  name,
+		Phase: phase,
+		Start: func(context.Context) error {
+			go func() {
+				err := f(ctx)
+				if err != nil && ctx.Err() == nil {
+					l.logger.Errorf("%s stopped: %v", name, err)
+					l.Shutdown()
+				}
+				done <- err
+			}()
+			return nil
+		},
+		Stop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case err := <-done:
+				return err
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+// Shutdown make Run stop the hooks, e.g. when a dependency the service can not live without is gone
+func (l *Lifecycle) Shutdown() {
+	l.cancel()
+}
+
+// Closer stop with close, such as cache.Cache.Close or sql.ORM.Close in PhaseStore
+func Closer(name string, phase Phase, close func() error) Hook {
+	return Hook{
+		Name:  name,
+		Phase: phase,
+		Stop: func(context.Context) error {
+			return close()
+		},
+	}
+}
+
+// HTTPServer serve srv in PhaseServer, Shutdown let the requests in flight finish before it return
+func HTTPServer(name string, srv *http.Server) Hook {
+	return Hook{
+		Name:  name,
+		Phase: PhaseServer,
+		Start: func(context.Context) error {
+			listener, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go srv.Serve(listener)
+			return nil
+		},
+		Stop: srv.Shutdown,
+	}
+}
+
+// GRPCServer serve srv on listener in PhaseServer, GracefulStop let the calls in flight finish,
+// the server is stopped hard when the hook time out
+func GRPCServer(name string, srv *grpc.Server, listener net.Listener) Hook {
+	return Hook{
+		Name:  name,
+		Phase: PhaseServer,
+		Start: func(context.Context) error {
+			go srv.Serve(listener)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// Monitor flush mntr in PhaseMonitor, after every other hook so the errors of the shutdown are sent
+func Monitor(mntr monitor.Monitor) Hook {
+	return Hook{
+		Name:  "monitor",
+		Phase: PhaseMonitor,
+		Stop: func(context.Context) error {
+			if !mntr.Flush() {
+				return ErrFlushTimeout
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,255 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/neazossa/common-util-go/logger/logger"
+)
+
+// Phase order the hooks, started in ascending order and stopped in descending order.
+// Hooks of the same phase are started in registration order and stopped in parallel.
+const (
+	PhaseMonitor   Phase = iota // monitor, started first and flushed last so the shutdown errors are sent
+	PhaseStore                  // redis, postgres, mongo, minio, kafka connection
+	PhaseConsumer               // kafka readers and workers, drained before the stores are closed
+	PhaseServer                 // http and grpc servers, stop accepting requests first
+	PhaseReadiness              // readiness probe, reported not ready before anything stops
+)
+
+const (
+	DefaultShutdownTimeout = 30 * time.Second
+	DefaultHookTimeout     = 10 * time.Second
+)
+
+type (
+	Phase int
+
+	// Hook is a module started and stopped by Lifecycle, Start and Stop are optional
+	// - Timeout bound Start and Stop of the hook, default Option.HookTimeout
+	Hook struct {
+		Name    string
+		Phase   Phase
+		Start   func(ctx context.Context) error
+		Stop    func(ctx context.Context) error
+		Timeout time.Duration
+	}
+
+	// Option configure NewLifecycle
+	// - ShutdownTimeout bound the whole shutdown, default 30 seconds
+	// - HookTimeout bound a single hook when it has no Timeout, default 10 seconds
+	// - ShutdownDelay wait after PhaseReadiness is stopped, so the load balancer see the service not ready
+	//   before the servers stop accepting requests
+	// - Signals trigger the shutdown in Run, default SIGINT and SIGTERM
+	Option struct {
+		ShutdownTimeout time.Duration
+		HookTimeout     time.Duration
+		ShutdownDelay   time.Duration
+		Signals         []os.Signal
+	}
+
+	// Lifecycle start the registered hooks, wait for a signal then stop them phase by phase
+	Lifecycle struct {
+		logger   logger.Logger
+		option   Option
+		mutex    sync.Mutex
+		hooks    []Hook
+		started  []Hook
+		ctx      context.Context
+		cancel   context.CancelFunc
+		stopOnce sync.Once
+		stopErr  error
+	}
+)
+
+func NewLifecycle(log logger.Logger, option Option) *Lifecycle {
+	if option.ShutdownTimeout <= 0 {
+		option.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	if option.HookTimeout <= 0 {
+		option.HookTimeout = DefaultHookTimeout
+	}
+	if len(option.Signals) == 0 {
+		option.Signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Lifecycle{
+		logger: log,
+		option: option,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Append register hook, hooks appended after Start are not started
+func (l *Lifecycle) Append(hook Hook) {
+	if hook.Timeout <= 0 {
+		hook.Timeout = l.option.HookTimeout
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.hooks = append(l.hooks, hook)
+}
+
+// Context is canceled when the shutdown begin, give it to the loops that must end on shutdown
+func (l *Lifecycle) Context() context.Context {
+	return l.ctx
+}
+
+// Start run the Start of every hook by ascending phase. When one fail, the hooks already started are stopped.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mutex.Lock()
+	hooks := append([]Hook(nil), l.hooks...)
+	l.mutex.Unlock()
+
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].Phase < hooks[j].Phase
+	})
+
+	for _, hook := range hooks {
+		if hook.Start != nil {
+			if err := l.run(ctx, hook, hook.Start); err != nil {
+				err = fmt.Errorf("failed to start %s: %w", hook.Name, err)
+				l.logger.Error(err)
+				return errors.Join(err, l.Stop(ctx))
+			}
+		}
+
+		l.mutex.Lock()
+		l.started = append(l.started, hook)
+		l.mutex.Unlock()
+	}
+	return nil
+}
+
+// Run start the hooks, wait for a signal or ctx to be done, then stop the hooks within ShutdownTimeout
+func (l *Lifecycle) Run(ctx context.Context) error {
+	if err := l.Start(ctx); err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, l.option.Signals...)
+	defer signal.Stop(signals)
+
+	select {
+	case sig := <-signals:
+		l.logger.Infof("received %s, shutting down", sig)
+	case <-ctx.Done():
+		l.logger.Info("context done, shutting down")
+	case <-l.ctx.Done():
+		l.logger.Info("shutdown requested, shutting down")
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), l.option.ShutdownTimeout)
+	defer cancel()
+	return l.Stop(stopCtx)
+}
+
+// Stop cancel Context, then run the Stop of the started hooks by descending phase. The hooks of a phase are
+// stopped in parallel and the next phase start when they all returned. Stop run once, the later calls return
+// the result of the first.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.stopOnce.Do(func() {
+		l.cancel()
+
+		l.mutex.Lock()
+		started := append([]Hook(nil), l.started...)
+		l.mutex.Unlock()
+
+		var errs []error
+		for _, phase := range phases(started) {
+			errs = append(errs, l.stopPhase(ctx, phase, started)...)
+
+			if phase == PhaseReadiness && l.option.ShutdownDelay > 0 {
+				select {
+				case <-time.After(l.option.ShutdownDelay):
+				case <-ctx.Done():
+				}
+			}
+		}
+		l.stopErr = errors.Join(errs...)
+	})
+	return l.stopErr
+}
+
+func (l *Lifecycle) stopPhase(ctx context.Context, phase Phase, hooks []Hook) []error {
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		errs  []error
+	)
+
+	for _, hook := range hooks {
+		if hook.Phase != phase || hook.Stop == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(hook Hook) {
+			defer wg.Done()
+
+			if err := l.run(ctx, hook, hook.Stop); err != nil {
+				err = fmt.Errorf("failed to stop %s: %w", hook.Name, err)
+				l.logger.Error(err)
+
+				mutex.Lock()
+				errs = append(errs, err)
+				mutex.Unlock()
+				return
+			}
+			l.logger.Infof("stopped %s", hook.Name)
+		}(hook)
+	}
+	wg.Wait()
+	return errs
+}
+
+// run call f within the timeout of hook, a hook not returning in time is left behind and reported as timed out
+func (l *Lifecycle) run(ctx context.Context, hook Hook, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- f(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// phases return the distinct phases of hooks in stop order
+func phases(hooks []Hook) []Phase {
+	seen := make(map[Phase]bool)
+	var result []Phase
+	for _, hook := range hooks {
+		if !seen[hook.Phase] {
+			seen[hook.Phase] = true
+			result = append(result, hook.Phase)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] > result[j]
+	})
+	return result
+}
@@ -3,14 +3,8 @@ module github.com/neazossa/common-util-go/logger/implementations/logrus
 go 1.20
 
 require (
-	github.com/lestrrat-go/file-rotatelogs v2.4.0+incompatible
-	github.com/neazossa/common-util-go/logger/logger v1.0.1
+	github.com/neazossa/common-util-go/logger/logger v1.1.0
 	github.com/sirupsen/logrus v1.9.0
 )
 
-require (
-	github.com/jonboulle/clockwork v0.3.0 // indirect
-	github.com/lestrrat-go/strftime v1.0.6 // indirect
-	github.com/pkg/errors v0.9.1 // indirect
-	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
-)
+require golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
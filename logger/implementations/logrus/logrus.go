@@ -1,13 +1,10 @@
 package logrus
 
 import (
-	"fmt"
+	"context"
 	"io"
 	"os"
-	"runtime"
-	"time"
 
-	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 	log "github.com/neazossa/common-util-go/logger/logger"
 	"github.com/sirupsen/logrus"
 )
@@ -15,46 +12,37 @@ import (
 type (
 	logger struct {
 		instance *logrus.Logger
+		writer   io.WriteCloser
+		levels   *log.LevelSet
+		module   string
 		data     map[string]interface{}
+		fields   map[string]interface{}
 	}
 
-	Level     string
-	Formatter string
-
-	Option struct {
-		Level       Level
-		LogFilePath string
-		Formatter   Formatter
-		MaxSize     int
-		MaxBackups  int
-		MaxAge      time.Duration
-		Compress    bool
-	}
+	// Level, Formatter and Option are shared by every logger implementation
+	Level     = log.Level
+	Formatter = log.Formatter
+	Option    = log.Option
 )
 
 const (
-	Info  Level = "INFO"
-	Debug Level = "DEBUG"
-	Error Level = "ERROR"
-
-	JSONFormatter Formatter = "JSON"
-	TextFormatter Formatter = "TEXT"
+	Trace = log.Trace
+	Debug = log.Debug
+	Info  = log.Info
+	Warn  = log.Warn
+	Error = log.Error
+	Fatal = log.Fatal
+	Panic = log.Panic
+
+	JSONFormatter = log.JSONFormatter
+	TextFormatter = log.TextFormatter
 )
 
 func New(option *Option) (log.Logger, error) {
 	instance := logrus.New()
 
-	if option.Level == Info {
-		instance.Level = logrus.InfoLevel
-	}
-
-	if option.Level == Debug {
-		instance.Level = logrus.DebugLevel
-	}
-
-	if option.Level == Error {
-		instance.Level = logrus.ErrorLevel
-	}
+	// - level is checked by the level set to support runtime and per module level
+	instance.Level = logrus.TraceLevel
 
 	var formatter logrus.Formatter
 
@@ -66,74 +54,138 @@ func New(option *Option) (log.Logger, error) {
 
 	instance.Formatter = formatter
 
-	// - check if log file path does exists
-	if option.LogFilePath != "" {
-		logf, err := rotatelogs.New(
-			option.LogFilePath+"/RequestResponseDump.log.%Y%m%d",
-			rotatelogs.WithLinkName(option.LogFilePath+"/RequestResponseDump.log"),
-			rotatelogs.WithRotationTime(24*time.Hour),
-			rotatelogs.WithMaxAge(option.MaxAge),
-		)
-
-		if err != nil {
-			return nil, err
-		}
-
-		instance.SetOutput(io.MultiWriter(os.Stdout, logf))
+	// - write to stdout, the rotated log file or both
+	writer, err := log.NewWriter(option)
+	if err != nil {
+		return nil, err
 	}
 
-	return &logger{instance, nil}, nil
-}
+	instance.SetOutput(writer)
 
-func fileInfo(skip int) string {
-	_, file, line, ok := runtime.Caller(skip)
-	if ok {
-		return fmt.Sprintf("%s:%d", file, line)
-	} else {
-		return ""
+	// - flush the queued entries before exiting on fatal
+	instance.ExitFunc = func(code int) {
+		_ = writer.Close()
+		os.Exit(code)
 	}
+
+	return &logger{instance: instance, writer: writer, levels: log.NewLevelSet(option.Level)}, nil
 }
 
 func (l *logger) createEntry() *logrus.Entry {
-	entry := l.instance.WithFields(logrus.Fields{})
+	entry := l.instance.WithFields(l.fields)
 	if l.data != nil {
-		entry = l.instance.WithFields(map[string]interface{}{
-			"data": l.data,
-		})
+		entry = entry.WithField("data", l.data)
 	}
-	entry.Data["file"] = fileInfo(3)
+	entry.Data["file"] = log.CallerInfo(0)
 	return entry
 }
 
+func (l *logger) enabled(level log.Level) bool {
+	return l.levels.Enabled(l.module, level)
+}
+
+func (l *logger) SetLevel(level log.Level) {
+	l.levels.Set(l.module, level)
+}
+
+func (l *logger) GetLevel() log.Level {
+	return l.levels.Get(l.module)
+}
+
+func (l *logger) Sync() error {
+	if s, ok := l.writer.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+func (l *logger) Close() error {
+	return l.writer.Close()
+}
+
+// Module create sub-logger with its own level, entries are tagged with the module name
+func (l *logger) Module(name string) log.Logger {
+	module := log.ModuleName(l.module, name)
+	return &logger{
+		instance: l.instance,
+		writer:   l.writer,
+		levels:   l.levels,
+		module:   module,
+		data:     l.data,
+		fields:   log.MergeFields(l.fields, map[string]interface{}{log.FieldModule: module}),
+	}
+}
+
+// WithFields merge data with the fields of previous WithFields call
 func (l *logger) WithFields(data map[string]interface{}) log.Logger {
 	return &logger{
 		instance: l.instance,
-		data:     data,
+		writer:   l.writer,
+		levels:   l.levels,
+		module:   l.module,
+		data:     log.MergeFields(l.data, data),
+		fields:   l.fields,
+	}
+}
+
+// WithContext add request id, trace / span id and user id found in context into every entry
+func (l *logger) WithContext(ctx context.Context) log.Logger {
+	return &logger{
+		instance: l.instance,
+		writer:   l.writer,
+		levels:   l.levels,
+		module:   l.module,
+		data:     l.data,
+		fields:   log.MergeFields(l.fields, log.FieldsFromContext(ctx)),
+	}
+}
+
+// WithError add error message, error chain and stack trace into every entry
+func (l *logger) WithError(err error) log.Logger {
+	return &logger{
+		instance: l.instance,
+		writer:   l.writer,
+		levels:   l.levels,
+		module:   l.module,
+		data:     l.data,
+		fields:   log.MergeFields(l.fields, log.ErrorFields(err)),
 	}
 }
 
 func (l *logger) Debugf(format string, args ...interface{}) {
-	l.createEntry().Debugf(format, args...)
+	if l.enabled(log.Debug) {
+		l.createEntry().Debugf(format, args...)
+	}
 }
 
 func (l *logger) Infof(format string, args ...interface{}) {
-	l.createEntry().Infof(format, args...)
+	if l.enabled(log.Info) {
+		l.createEntry().Infof(format, args...)
+	}
 }
 
 func (l *logger) Printf(format string, args ...interface{}) {
-	l.createEntry().Printf(format, args...)
+	if l.enabled(log.Info) {
+		l.createEntry().Printf(format, args...)
+	}
 }
 
 func (l *logger) Warnf(format string, args ...interface{}) {
-	l.createEntry().Warnf(format, args...)
+	if l.enabled(log.Warn) {
+		l.createEntry().Warnf(format, args...)
+	}
 }
 
 func (l *logger) Warningf(format string, args ...interface{}) {
-	l.createEntry().Warningf(format, args...)
+	if l.enabled(log.Warn) {
+		l.createEntry().Warningf(format, args...)
+	}
 }
 
 func (l *logger) Errorf(format string, args ...interface{}) {
-	l.createEntry().Errorf(format, args...)
+	if l.enabled(log.Error) {
+		l.createEntry().Errorf(format, args...)
+	}
 }
 
 func (l *logger) Fatalf(format string, args ...interface{}) {
@@ -141,31 +193,44 @@ func (l *logger) Fatalf(format string, args ...interface{}) {
 }
 
 func (l *logger) Panicf(format string, args ...interface{}) {
+	defer l.Sync()
 	l.createEntry().Panicf(format, args...)
 }
 
 func (l *logger) Debug(args ...interface{}) {
-	l.createEntry().Debug(args...)
+	if l.enabled(log.Debug) {
+		l.createEntry().Debug(args...)
+	}
 }
 
 func (l *logger) Info(args ...interface{}) {
-	l.createEntry().Info(args...)
+	if l.enabled(log.Info) {
+		l.createEntry().Info(args...)
+	}
 }
 
 func (l *logger) Print(args ...interface{}) {
-	l.createEntry().Print(args...)
+	if l.enabled(log.Info) {
+		l.createEntry().Print(args...)
+	}
 }
 
 func (l *logger) Warn(args ...interface{}) {
-	l.createEntry().Warn(args...)
+	if l.enabled(log.Warn) {
+		l.createEntry().Warn(args...)
+	}
 }
 
 func (l *logger) Warning(args ...interface{}) {
-	l.createEntry().Warning(args...)
+	if l.enabled(log.Warn) {
+		l.createEntry().Warning(args...)
+	}
 }
 
 func (l *logger) Error(args ...interface{}) {
-	l.createEntry().Error(args...)
+	if l.enabled(log.Error) {
+		l.createEntry().Error(args...)
+	}
 }
 
 func (l *logger) Fatal(args ...interface{}) {
@@ -173,31 +238,44 @@ func (l *logger) Fatal(args ...interface{}) {
 }
 
 func (l *logger) Panic(args ...interface{}) {
+	defer l.Sync()
 	l.createEntry().Panic(args...)
 }
 
 func (l *logger) Debugln(args ...interface{}) {
-	l.createEntry().Debugln(args...)
+	if l.enabled(log.Debug) {
+		l.createEntry().Debugln(args...)
+	}
 }
 
 func (l *logger) Infoln(args ...interface{}) {
-	l.createEntry().Infoln(args...)
+	if l.enabled(log.Info) {
+		l.createEntry().Infoln(args...)
+	}
 }
 
 func (l *logger) Println(args ...interface{}) {
-	l.createEntry().Println(args...)
+	if l.enabled(log.Info) {
+		l.createEntry().Println(args...)
+	}
 }
 
 func (l *logger) Warnln(args ...interface{}) {
-	l.createEntry().Warnln(args...)
+	if l.enabled(log.Warn) {
+		l.createEntry().Warnln(args...)
+	}
 }
 
 func (l *logger) Warningln(args ...interface{}) {
-	l.createEntry().Warningln(args...)
+	if l.enabled(log.Warn) {
+		l.createEntry().Warningln(args...)
+	}
 }
 
 func (l *logger) Errorln(args ...interface{}) {
-	l.createEntry().Errorln(args...)
+	if l.enabled(log.Error) {
+		l.createEntry().Errorln(args...)
+	}
 }
 
 func (l *logger) Fatalln(args ...interface{}) {
@@ -205,9 +283,12 @@ func (l *logger) Fatalln(args ...interface{}) {
 }
 
 func (l *logger) Panicln(args ...interface{}) {
+	defer l.Sync()
 	l.createEntry().Panicln(args...)
 }
 
 func (l *logger) Trace(args ...interface{}) {
-	l.createEntry().Trace(args...)
+	if l.enabled(log.Trace) {
+		l.createEntry().Trace(args...)
+	}
 }
@@ -0,0 +1,5 @@
+module github.com/neazossa/common-util-go/logger/implementations/slog
+
+go 1.21
+
+require github.com/neazossa/common-util-go/logger/logger v1.1.0
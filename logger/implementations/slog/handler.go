@@ -0,0 +1,110 @@
+package slog
+
+import (
+	"context"
+	"log/slog"
+
+	log "github.com/neazossa/common-util-go/logger/logger"
+)
+
+type (
+	handler struct {
+		logger log.Logger
+		attrs  map[string]interface{}
+		groups []string
+	}
+)
+
+// NewHandler create slog.Handler writing every record through logger.Logger,
+// so libraries using slog end up in the same log output
+func NewHandler(logger log.Logger) slog.Handler {
+	return &handler{logger: logger}
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.GetLevel().Enabled(Level(level))
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	fields := log.MergeFields(h.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		h.put(fields, attr)
+		return true
+	})
+
+	l := h.logger.WithContext(ctx)
+	if len(fields) > 0 {
+		l = l.WithFields(fields)
+	}
+
+	switch Level(record.Level) {
+	case log.Trace:
+		l.Trace(record.Message)
+	case log.Debug:
+		l.Debug(record.Message)
+	case log.Info:
+		l.Info(record.Message)
+	case log.Warn:
+		l.Warn(record.Message)
+	default:
+		// - a record above ERROR from a library never stop the process
+		l.Error(record.Message)
+	}
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := log.MergeFields(h.attrs)
+	for _, attr := range attrs {
+		h.put(fields, attr)
+	}
+
+	return &handler{
+		logger: h.logger,
+		attrs:  fields,
+		groups: h.groups,
+	}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &handler{
+		logger: h.logger,
+		attrs:  h.attrs,
+		groups: append(append([]string{}, h.groups...), name),
+	}
+}
+
+// put add attribute into fields under the current groups
+func (h *handler) put(fields map[string]interface{}, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	target := fields
+	for _, group := range h.groups {
+		// - copy the group to keep the fields of parent handler untouched
+		nested, _ := target[group].(map[string]interface{})
+		nested = log.MergeFields(nested)
+		target[group] = nested
+		target = nested
+	}
+
+	target[attr.Key] = attrValue(attr.Value)
+}
+
+func attrValue(value slog.Value) interface{} {
+	if value.Kind() != slog.KindGroup {
+		return value.Any()
+	}
+
+	group := make(map[string]interface{})
+	for _, attr := range value.Group() {
+		group[attr.Key] = attrValue(attr.Value.Resolve())
+	}
+	return group
+}
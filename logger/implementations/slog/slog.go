@@ -0,0 +1,296 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/neazossa/common-util-go/logger/logger"
+)
+
+const (
+	LevelTrace = slog.LevelDebug - 4
+	LevelFatal = slog.LevelError + 4
+	LevelPanic = slog.LevelError + 8
+)
+
+var (
+	// levelNames print the levels slog has no name for, instead of DEBUG-4 or ERROR+4
+	levelNames = map[slog.Level]log.Level{
+		LevelTrace: log.Trace,
+		LevelFatal: log.Fatal,
+		LevelPanic: log.Panic,
+	}
+)
+
+// Level convert slog level into logger level
+func Level(level slog.Level) log.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return log.Trace
+	case level < slog.LevelInfo:
+		return log.Debug
+	case level < slog.LevelWarn:
+		return log.Info
+	case level < slog.LevelError:
+		return log.Warn
+	case level < LevelFatal:
+		return log.Error
+	case level < LevelPanic:
+		return log.Fatal
+	default:
+		return log.Panic
+	}
+}
+
+type (
+	logger struct {
+		base     slog.Handler
+		instance slog.Handler
+		writer   io.WriteCloser
+		levels   *log.LevelSet
+		module   string
+		data     map[string]interface{}
+		fields   map[string]interface{}
+	}
+)
+
+// New create logger.Logger writing through slog JSON or text handler
+func New(option *log.Option) (log.Logger, error) {
+	// - write to stdout, the rotated log file or both
+	writer, err := log.NewWriter(option)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		handler        slog.Handler
+		handlerOptions = &slog.HandlerOptions{AddSource: true, Level: LevelTrace, ReplaceAttr: replaceLevel}
+	)
+
+	if option.Formatter == log.JSONFormatter {
+		handler = slog.NewJSONHandler(writer, handlerOptions)
+	} else {
+		handler = slog.NewTextHandler(writer, handlerOptions)
+	}
+
+	// - level is checked by the level set to support runtime and per module level
+	return &logger{base: handler, instance: handler, writer: writer, levels: log.NewLevelSet(option.Level)}, nil
+}
+
+// replaceLevel print TRACE, FATAL and PANIC instead of DEBUG-4, ERROR+4 and ERROR+8
+func replaceLevel(groups []string, attr slog.Attr) slog.Attr {
+	if level, ok := attr.Value.Any().(slog.Level); ok && attr.Key == slog.LevelKey && len(groups) == 0 {
+		if name, ok := levelNames[level]; ok {
+			attr.Value = slog.StringValue(string(name))
+		}
+	}
+	return attr
+}
+
+// NewWithHandler create logger.Logger backed by any slog.Handler,
+// the level of the handler still applies on top of the logger level which start at TRACE
+func NewWithHandler(handler slog.Handler) log.Logger {
+	return &logger{base: handler, instance: handler, levels: log.NewLevelSet(log.Trace)}
+}
+
+// with prepare the handler once, so the fields are not converted on every call
+func (l *logger) with(module string, data, fields map[string]interface{}) *logger {
+	attrs := make([]slog.Attr, 0, len(fields)+1)
+	for key, value := range fields {
+		attrs = append(attrs, slog.Any(key, value))
+	}
+
+	if data != nil {
+		attrs = append(attrs, slog.Any("data", data))
+	}
+
+	return &logger{
+		base:     l.base,
+		instance: l.base.WithAttrs(attrs),
+		writer:   l.writer,
+		levels:   l.levels,
+		module:   module,
+		data:     data,
+		fields:   fields,
+	}
+}
+
+// log write the record, fatal and panic are always written like zap since the process stop right after
+func (l *logger) log(level slog.Level, msg string) {
+	ctx := context.Background()
+	if level < LevelFatal && !l.levels.Enabled(l.module, Level(level)) || !l.instance.Enabled(ctx, level) {
+		return
+	}
+
+	pc, _, _ := log.Caller(0)
+	record := slog.NewRecord(time.Now(), level, msg, pc)
+	_ = l.instance.Handle(ctx, record)
+}
+
+// Sync flush the queued entries, a logger created by NewWithHandler own no writer
+func (l *logger) Sync() error {
+	if s, ok := l.writer.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+func (l *logger) Close() error {
+	if l.writer == nil {
+		return nil
+	}
+	return l.writer.Close()
+}
+
+func (l *logger) SetLevel(level log.Level) {
+	l.levels.Set(l.module, level)
+}
+
+func (l *logger) GetLevel() log.Level {
+	return l.levels.Get(l.module)
+}
+
+// Module create sub-logger with its own level, entries are tagged with the module name
+func (l *logger) Module(name string) log.Logger {
+	module := log.ModuleName(l.module, name)
+	return l.with(module, l.data, log.MergeFields(l.fields, map[string]interface{}{log.FieldModule: module}))
+}
+
+// WithFields merge data with the fields of previous WithFields call
+func (l *logger) WithFields(data map[string]interface{}) log.Logger {
+	return l.with(l.module, log.MergeFields(l.data, data), l.fields)
+}
+
+// WithContext add request id, trace / span id and user id found in context into every entry
+func (l *logger) WithContext(ctx context.Context) log.Logger {
+	return l.with(l.module, l.data, log.MergeFields(l.fields, log.FieldsFromContext(ctx)))
+}
+
+// WithError add error message, error chain and stack trace into every entry
+func (l *logger) WithError(err error) log.Logger {
+	return l.with(l.module, l.data, log.MergeFields(l.fields, log.ErrorFields(err)))
+}
+
+func sprintln(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
+func (l *logger) Debugf(format string, args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Infof(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Printf(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Warnf(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Warningf(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Errorf(format string, args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Fatalf(format string, args ...interface{}) {
+	l.log(LevelFatal, fmt.Sprintf(format, args...))
+	_ = l.Close()
+	os.Exit(1)
+}
+
+func (l *logger) Panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(LevelPanic, msg)
+	_ = l.Sync()
+	panic(msg)
+}
+
+func (l *logger) Debug(args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprint(args...))
+}
+
+func (l *logger) Info(args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+func (l *logger) Print(args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+func (l *logger) Warn(args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (l *logger) Warning(args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (l *logger) Error(args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprint(args...))
+}
+
+func (l *logger) Fatal(args ...interface{}) {
+	l.log(LevelFatal, fmt.Sprint(args...))
+	_ = l.Close()
+	os.Exit(1)
+}
+
+func (l *logger) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	l.log(LevelPanic, msg)
+	_ = l.Sync()
+	panic(msg)
+}
+
+func (l *logger) Debugln(args ...interface{}) {
+	l.log(slog.LevelDebug, sprintln(args...))
+}
+
+func (l *logger) Infoln(args ...interface{}) {
+	l.log(slog.LevelInfo, sprintln(args...))
+}
+
+func (l *logger) Println(args ...interface{}) {
+	l.log(slog.LevelInfo, sprintln(args...))
+}
+
+func (l *logger) Warnln(args ...interface{}) {
+	l.log(slog.LevelWarn, sprintln(args...))
+}
+
+func (l *logger) Warningln(args ...interface{}) {
+	l.log(slog.LevelWarn, sprintln(args...))
+}
+
+func (l *logger) Errorln(args ...interface{}) {
+	l.log(slog.LevelError, sprintln(args...))
+}
+
+func (l *logger) Fatalln(args ...interface{}) {
+	l.log(LevelFatal, sprintln(args...))
+	_ = l.Close()
+	os.Exit(1)
+}
+
+func (l *logger) Panicln(args ...interface{}) {
+	msg := sprintln(args...)
+	l.log(LevelPanic, msg)
+	_ = l.Sync()
+	panic(msg)
+}
+
+func (l *logger) Trace(args ...interface{}) {
+	l.log(LevelTrace, fmt.Sprint(args...))
+}
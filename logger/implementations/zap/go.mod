@@ -0,0 +1,13 @@
+module github.com/neazossa/common-util-go/logger/implementations/zap
+
+go 1.20
+
+require (
+	github.com/neazossa/common-util-go/logger/logger v1.1.0
+	go.uber.org/zap v1.24.0
+)
+
+require (
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+)
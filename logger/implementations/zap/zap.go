@@ -0,0 +1,246 @@
+package zap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/neazossa/common-util-go/logger/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	logger struct {
+		base     *zap.Logger
+		instance *zap.Logger
+		writer   io.WriteCloser
+		levels   *log.LevelSet
+		module   string
+		data     map[string]interface{}
+		fields   map[string]interface{}
+	}
+
+	// exitHook flush the queued entries before exiting on fatal
+	exitHook struct {
+		writer io.Closer
+	}
+)
+
+func (h exitHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	_ = h.writer.Close()
+	os.Exit(1)
+}
+
+func New(option *log.Option) (log.Logger, error) {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.CallerKey = "file"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+
+	var encoder zapcore.Encoder
+
+	if option.Formatter == log.JSONFormatter {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	}
+
+	// - write to stdout, the rotated log file or both
+	writer, err := log.NewWriter(option)
+	if err != nil {
+		return nil, err
+	}
+
+	// - level is checked by the level set to support runtime and per module level
+	core := zapcore.NewCore(encoder, zapcore.AddSync(writer), zapcore.DebugLevel)
+	instance := zap.New(core, zap.WithFatalHook(exitHook{writer}))
+
+	return &logger{base: instance, instance: instance, writer: writer, levels: log.NewLevelSet(option.Level)}, nil
+}
+
+// with prepare the logger once, so the fields are not encoded on every call
+func (l *logger) with(module string, data, fields map[string]interface{}) *logger {
+	zapFields := make([]zap.Field, 0, len(fields)+1)
+	for key, value := range fields {
+		zapFields = append(zapFields, zap.Any(key, value))
+	}
+
+	if data != nil {
+		zapFields = append(zapFields, zap.Any("data", data))
+	}
+
+	return &logger{
+		base:     l.base,
+		instance: l.base.With(zapFields...),
+		writer:   l.writer,
+		levels:   l.levels,
+		module:   module,
+		data:     data,
+		fields:   fields,
+	}
+}
+
+// log write the entry with the caller outside the logger packages, so decorators don't hide the call site
+func (l *logger) log(level log.Level, zapLevel zapcore.Level, msg string) {
+	if level != log.Fatal && level != log.Panic && !l.levels.Enabled(l.module, level) {
+		return
+	}
+
+	// - panic is raised right after the write, flush the queued entries while unwinding
+	if level == log.Panic {
+		defer l.Sync()
+	}
+
+	if entry := l.instance.Check(zapLevel, msg); entry != nil {
+		pc, file, line := log.Caller(0)
+		entry.Caller = zapcore.NewEntryCaller(pc, file, line, file != "")
+		entry.Write()
+	}
+}
+
+func (l *logger) SetLevel(level log.Level) {
+	l.levels.Set(l.module, level)
+}
+
+func (l *logger) GetLevel() log.Level {
+	return l.levels.Get(l.module)
+}
+
+func (l *logger) Sync() error {
+	return l.base.Sync()
+}
+
+func (l *logger) Close() error {
+	_ = l.base.Sync()
+	return l.writer.Close()
+}
+
+// Module create sub-logger with its own level, entries are tagged with the module name
+func (l *logger) Module(name string) log.Logger {
+	module := log.ModuleName(l.module, name)
+	return l.with(module, l.data, log.MergeFields(l.fields, map[string]interface{}{log.FieldModule: module}))
+}
+
+// WithFields merge data with the fields of previous WithFields call
+func (l *logger) WithFields(data map[string]interface{}) log.Logger {
+	return l.with(l.module, log.MergeFields(l.data, data), l.fields)
+}
+
+// WithContext add request id, trace / span id and user id found in context into every entry
+func (l *logger) WithContext(ctx context.Context) log.Logger {
+	return l.with(l.module, l.data, log.MergeFields(l.fields, log.FieldsFromContext(ctx)))
+}
+
+// WithError add error message, error chain and stack trace into every entry
+func (l *logger) WithError(err error) log.Logger {
+	return l.with(l.module, l.data, log.MergeFields(l.fields, log.ErrorFields(err)))
+}
+
+func sprintln(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
+func (l *logger) Debugf(format string, args ...interface{}) {
+	l.log(log.Debug, zapcore.DebugLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Infof(format string, args ...interface{}) {
+	l.log(log.Info, zapcore.InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Printf(format string, args ...interface{}) {
+	l.log(log.Info, zapcore.InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Warnf(format string, args ...interface{}) {
+	l.log(log.Warn, zapcore.WarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Warningf(format string, args ...interface{}) {
+	l.log(log.Warn, zapcore.WarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Errorf(format string, args ...interface{}) {
+	l.log(log.Error, zapcore.ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Fatalf(format string, args ...interface{}) {
+	l.log(log.Fatal, zapcore.FatalLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Panicf(format string, args ...interface{}) {
+	l.log(log.Panic, zapcore.PanicLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *logger) Debug(args ...interface{}) {
+	l.log(log.Debug, zapcore.DebugLevel, fmt.Sprint(args...))
+}
+
+func (l *logger) Info(args ...interface{}) {
+	l.log(log.Info, zapcore.InfoLevel, fmt.Sprint(args...))
+}
+
+func (l *logger) Print(args ...interface{}) {
+	l.log(log.Info, zapcore.InfoLevel, fmt.Sprint(args...))
+}
+
+func (l *logger) Warn(args ...interface{}) {
+	l.log(log.Warn, zapcore.WarnLevel, fmt.Sprint(args...))
+}
+
+func (l *logger) Warning(args ...interface{}) {
+	l.log(log.Warn, zapcore.WarnLevel, fmt.Sprint(args...))
+}
+
+func (l *logger) Error(args ...interface{}) {
+	l.log(log.Error, zapcore.ErrorLevel, fmt.Sprint(args...))
+}
+
+func (l *logger) Fatal(args ...interface{}) {
+	l.log(log.Fatal, zapcore.FatalLevel, fmt.Sprint(args...))
+}
+
+func (l *logger) Panic(args ...interface{}) {
+	l.log(log.Panic, zapcore.PanicLevel, fmt.Sprint(args...))
+}
+
+func (l *logger) Debugln(args ...interface{}) {
+	l.log(log.Debug, zapcore.DebugLevel, sprintln(args...))
+}
+
+func (l *logger) Infoln(args ...interface{}) {
+	l.log(log.Info, zapcore.InfoLevel, sprintln(args...))
+}
+
+func (l *logger) Println(args ...interface{}) {
+	l.log(log.Info, zapcore.InfoLevel, sprintln(args...))
+}
+
+func (l *logger) Warnln(args ...interface{}) {
+	l.log(log.Warn, zapcore.WarnLevel, sprintln(args...))
+}
+
+func (l *logger) Warningln(args ...interface{}) {
+	l.log(log.Warn, zapcore.WarnLevel, sprintln(args...))
+}
+
+func (l *logger) Errorln(args ...interface{}) {
+	l.log(log.Error, zapcore.ErrorLevel, sprintln(args...))
+}
+
+func (l *logger) Fatalln(args ...interface{}) {
+	l.log(log.Fatal, zapcore.FatalLevel, sprintln(args...))
+}
+
+func (l *logger) Panicln(args ...interface{}) {
+	l.log(log.Panic, zapcore.PanicLevel, sprintln(args...))
+}
+
+// Trace is logged as debug, zap has no trace level
+func (l *logger) Trace(args ...interface{}) {
+	l.log(log.Trace, zapcore.DebugLevel, fmt.Sprint(args...))
+}
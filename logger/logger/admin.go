@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+)
+
+type (
+	levelResponse struct {
+		Module string `json:"module,omitempty"`
+		Level  Level  `json:"level"`
+		Error  string `json:"error,omitempty"`
+	}
+)
+
+// LevelHandler expose the log level over http, mount it on an internal admin port:
+//
+//	GET  ?module=kafka             return the current level
+//	PUT  ?module=kafka&level=debug change the level, module is optional
+func LevelHandler(l Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			module = r.FormValue("module")
+			target = l
+		)
+
+		if module != "" {
+			target = l.Module(module)
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			level, err := ParseLevel(r.FormValue("level"))
+			if err != nil {
+				writeLevelResponse(w, http.StatusBadRequest, levelResponse{Module: module, Level: target.GetLevel(), Error: err.Error()})
+				return
+			}
+			target.SetLevel(level)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeLevelResponse(w, http.StatusOK, levelResponse{Module: module, Level: target.GetLevel()})
+	})
+}
+
+func writeLevelResponse(w http.ResponseWriter, status int, response levelResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// ToggleDebugOnSignal switch the logger between debug and its previous level every time one of the signals
+// is received, e.g. `ToggleDebugOnSignal(l, syscall.SIGUSR1)` then `kill -USR1 <pid>`.
+// Call the returned function to stop listening.
+func ToggleDebugOnSignal(l Logger, signals ...os.Signal) func() {
+	var (
+		ch       = make(chan os.Signal, 1)
+		done     = make(chan struct{})
+		previous = l.GetLevel()
+	)
+
+	if len(signals) == 0 {
+		return func() {}
+	}
+
+	if previous == Debug {
+		previous = Info
+	}
+
+	signal.Notify(ch, signals...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if current := l.GetLevel(); current != Debug {
+					previous = current
+					l.SetLevel(Debug)
+				} else {
+					l.SetLevel(previous)
+				}
+				l.Warnf("log level changed to %s", l.GetLevel())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
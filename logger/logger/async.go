@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	OverflowBlock OverflowPolicy = "BLOCK"
+	OverflowDrop  OverflowPolicy = "DROP"
+
+	DefaultAsyncQueueSize = 1024
+)
+
+type (
+	// OverflowPolicy decide what happens to an entry when the async queue is full
+	OverflowPolicy string
+
+	AsyncOption struct {
+		QueueSize int            // entries, default DefaultAsyncQueueSize
+		Overflow  OverflowPolicy // default OverflowBlock
+	}
+
+	// AsyncWriter write into the wrapped writer from a single goroutine through a bounded queue
+	AsyncWriter struct {
+		writer  io.Writer
+		policy  OverflowPolicy
+		queue   chan asyncEntry
+		done    chan struct{}
+		mutex   sync.RWMutex
+		closed  bool
+		dropped uint64
+	}
+
+	asyncEntry struct {
+		data    []byte
+		flushed chan struct{}
+	}
+
+	syncer interface {
+		Sync() error
+	}
+)
+
+var (
+	droppedEntries uint64
+)
+
+// DroppedEntries return the entries dropped by every AsyncWriter since the process started
+func DroppedEntries() uint64 {
+	return atomic.LoadUint64(&droppedEntries)
+}
+
+func NewAsyncWriter(writer io.Writer, option AsyncOption) *AsyncWriter {
+	if option.QueueSize <= 0 {
+		option.QueueSize = DefaultAsyncQueueSize
+	}
+
+	if option.Overflow != OverflowDrop {
+		option.Overflow = OverflowBlock
+	}
+
+	w := &AsyncWriter{
+		writer: writer,
+		policy: option.Overflow,
+		queue:  make(chan asyncEntry, option.QueueSize),
+		done:   make(chan struct{}),
+	}
+
+	go w.run()
+	return w
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+
+	for entry := range w.queue {
+		if entry.flushed != nil {
+			close(entry.flushed)
+			continue
+		}
+		_, _ = w.writer.Write(entry.data)
+	}
+}
+
+// Write queue a copy of p, the entry is dropped when the queue is full and the policy is OverflowDrop
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	entry := asyncEntry{data: append([]byte(nil), p...)}
+	if w.policy == OverflowBlock {
+		w.queue <- entry
+		return len(p), nil
+	}
+
+	select {
+	case w.queue <- entry:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+		atomic.AddUint64(&droppedEntries, 1)
+	}
+	return len(p), nil
+}
+
+// Dropped return the entries dropped by this writer
+func (w *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Sync wait until every queued entry is written, then sync the wrapped writer
+func (w *AsyncWriter) Sync() error {
+	w.mutex.RLock()
+	if w.closed {
+		w.mutex.RUnlock()
+		return nil
+	}
+
+	flushed := make(chan struct{})
+	w.queue <- asyncEntry{flushed: flushed}
+	w.mutex.RUnlock()
+
+	<-flushed
+	return syncWriter(w.writer)
+}
+
+// Close write the queued entries then close the wrapped writer
+func (w *AsyncWriter) Close() error {
+	w.mutex.Lock()
+	if w.closed {
+		w.mutex.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mutex.Unlock()
+
+	<-w.done
+	if closer, ok := w.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return syncWriter(w.writer)
+}
+
+func syncWriter(w io.Writer) error {
+	if s, ok := w.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	callerMutex        sync.RWMutex
+	callerSkipPrefixes = []string{
+		"github.com/neazossa/common-util-go/logger/",
+		"log/slog.",
+	}
+)
+
+// RegisterCallerSkip ignore the frames of package prefix when looking up the caller,
+// used by logger decorators living outside the logger module
+func RegisterCallerSkip(prefix string) {
+	callerMutex.Lock()
+	defer callerMutex.Unlock()
+	callerSkipPrefixes = append(callerSkipPrefixes, prefix)
+}
+
+// Caller return the program counter, file and line of the first frame outside the logger packages
+func Caller(skip int) (uintptr, string, int) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	callerMutex.RLock()
+	defer callerMutex.RUnlock()
+
+	for {
+		frame, more := frames.Next()
+		if !isSkipped(frame.Function) {
+			return frame.PC, frame.File, frame.Line
+		}
+
+		if !more {
+			return 0, "", 0
+		}
+	}
+}
+
+// CallerInfo return `file:line` of Caller
+func CallerInfo(skip int) string {
+	_, file, line := Caller(skip + 1)
+	if file == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+func isSkipped(function string) bool {
+	for _, prefix := range callerSkipPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	FieldRequestID = "request_id"
+	FieldTraceID   = "trace_id"
+	FieldSpanID    = "span_id"
+	FieldUserID    = "user_id"
+)
+
+type (
+	// ContextExtractor pull fields from context, used by WithContext for every entry
+	ContextExtractor func(ctx context.Context) map[string]interface{}
+)
+
+var (
+	extractorMutex sync.RWMutex
+	extractors     []ContextExtractor
+)
+
+// RegisterContextExtractor add extractor used by FieldsFromContext, e.g. requestctx register an extractor
+// for the request and user id, monitor implementation for the trace and span id of the current transaction
+func RegisterContextExtractor(extractor ContextExtractor) {
+	extractorMutex.Lock()
+	defer extractorMutex.Unlock()
+	extractors = append(extractors, extractor)
+}
+
+// FieldsFromContext collect fields from context using all registered extractors
+func FieldsFromContext(ctx context.Context) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if ctx == nil {
+		return fields
+	}
+
+	extractorMutex.RLock()
+	defer extractorMutex.RUnlock()
+
+	for _, extractor := range extractors {
+		for key, value := range extractor(ctx) {
+			fields[key] = value
+		}
+	}
+	return fields
+}
@@ -0,0 +1,318 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	KindPrint EntryKind = iota
+	KindFormat
+	KindLine
+)
+
+type (
+	// EntryKind tell how the arguments are rendered, like Info, Infof or Infoln
+	EntryKind int
+
+	// Entry is a single log call seen by Interceptor
+	Entry struct {
+		Level   Level
+		Kind    EntryKind
+		Format  string
+		Args    []interface{}
+		Fields  map[string]interface{} // merged data of every WithFields call
+		Context context.Context
+		Err     error
+		Module  string
+		Caller  string
+		Enabled bool // whether the level of the wrapped logger allows the entry
+	}
+
+	// Interceptor is called for every entry before it reaches the wrapped logger,
+	// returning false drop the entry. Fatal and panic entries are never dropped.
+	Interceptor interface {
+		Intercept(entry *Entry) bool
+	}
+
+	// FieldsInterceptor is optionally implemented by Interceptor to change fields before they reach the wrapped logger
+	FieldsInterceptor interface {
+		InterceptFields(fields map[string]interface{}) map[string]interface{}
+	}
+
+	decorator struct {
+		logger      Logger
+		interceptor Interceptor
+		entry       Entry
+	}
+)
+
+// Decorate wrap logger, every entry goes through interceptor first
+func Decorate(l Logger, interceptor Interceptor) Logger {
+	return &decorator{
+		logger:      l,
+		interceptor: interceptor,
+	}
+}
+
+// Message render the entry like the wrapped logger would
+func (e *Entry) Message() string {
+	switch e.Kind {
+	case KindFormat:
+		return fmt.Sprintf(e.Format, e.Args...)
+	case KindLine:
+		return strings.TrimSuffix(fmt.Sprintln(e.Args...), "\n")
+	default:
+		return fmt.Sprint(e.Args...)
+	}
+}
+
+// Template return the format, or the first argument when it is a string, to group entries of the same log site
+func (e *Entry) Template() string {
+	if e.Kind == KindFormat {
+		return e.Format
+	}
+
+	if len(e.Args) > 0 {
+		if msg, ok := e.Args[0].(string); ok {
+			return msg
+		}
+	}
+	return e.Message()
+}
+
+func (d *decorator) with(l Logger, entry Entry) *decorator {
+	return &decorator{
+		logger:      l,
+		interceptor: d.interceptor,
+		entry:       entry,
+	}
+}
+
+func (d *decorator) log(level Level, kind EntryKind, format string, args []interface{}) {
+	entry := d.entry
+	entry.Level = level
+	entry.Kind = kind
+	entry.Format = format
+	entry.Args = args
+	entry.Caller = CallerInfo(2)
+	entry.Enabled = d.logger.GetLevel().Enabled(level)
+
+	if !d.interceptor.Intercept(&entry) && level != Fatal && level != Panic {
+		return
+	}
+	emit(d.logger, &entry)
+}
+
+func emit(l Logger, e *Entry) {
+	switch e.Kind {
+	case KindFormat:
+		emitFormat(l, e.Level, e.Format, e.Args)
+	case KindLine:
+		emitLine(l, e.Level, e.Args)
+	default:
+		emitPrint(l, e.Level, e.Args)
+	}
+}
+
+func emitFormat(l Logger, level Level, format string, args []interface{}) {
+	switch level {
+	case Trace:
+		l.Trace(fmt.Sprintf(format, args...))
+	case Debug:
+		l.Debugf(format, args...)
+	case Warn:
+		l.Warnf(format, args...)
+	case Error:
+		l.Errorf(format, args...)
+	case Fatal:
+		l.Fatalf(format, args...)
+	case Panic:
+		l.Panicf(format, args...)
+	default:
+		l.Infof(format, args...)
+	}
+}
+
+func emitLine(l Logger, level Level, args []interface{}) {
+	switch level {
+	case Trace:
+		l.Trace(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+	case Debug:
+		l.Debugln(args...)
+	case Warn:
+		l.Warnln(args...)
+	case Error:
+		l.Errorln(args...)
+	case Fatal:
+		l.Fatalln(args...)
+	case Panic:
+		l.Panicln(args...)
+	default:
+		l.Infoln(args...)
+	}
+}
+
+func emitPrint(l Logger, level Level, args []interface{}) {
+	switch level {
+	case Trace:
+		l.Trace(args...)
+	case Debug:
+		l.Debug(args...)
+	case Warn:
+		l.Warn(args...)
+	case Error:
+		l.Error(args...)
+	case Fatal:
+		l.Fatal(args...)
+	case Panic:
+		l.Panic(args...)
+	default:
+		l.Info(args...)
+	}
+}
+
+func (d *decorator) WithFields(data map[string]interface{}) Logger {
+	entry := d.entry
+	entry.Fields = MergeFields(entry.Fields, data)
+
+	if fi, ok := d.interceptor.(FieldsInterceptor); ok {
+		data = fi.InterceptFields(data)
+	}
+	return d.with(d.logger.WithFields(data), entry)
+}
+
+func (d *decorator) WithContext(ctx context.Context) Logger {
+	entry := d.entry
+	entry.Context = ctx
+	return d.with(d.logger.WithContext(ctx), entry)
+}
+
+func (d *decorator) WithError(err error) Logger {
+	entry := d.entry
+	entry.Err = err
+	return d.with(d.logger.WithError(err), entry)
+}
+
+func (d *decorator) SetLevel(level Level) {
+	d.logger.SetLevel(level)
+}
+
+func (d *decorator) GetLevel() Level {
+	return d.logger.GetLevel()
+}
+
+func (d *decorator) Module(name string) Logger {
+	entry := d.entry
+	entry.Module = ModuleName(entry.Module, name)
+	return d.with(d.logger.Module(name), entry)
+}
+
+func (d *decorator) Sync() error {
+	return d.logger.Sync()
+}
+
+func (d *decorator) Close() error {
+	return d.logger.Close()
+}
+
+func (d *decorator) Debugf(format string, args ...interface{}) {
+	d.log(Debug, KindFormat, format, args)
+}
+
+func (d *decorator) Infof(format string, args ...interface{}) {
+	d.log(Info, KindFormat, format, args)
+}
+
+func (d *decorator) Printf(format string, args ...interface{}) {
+	d.log(Info, KindFormat, format, args)
+}
+
+func (d *decorator) Warnf(format string, args ...interface{}) {
+	d.log(Warn, KindFormat, format, args)
+}
+
+func (d *decorator) Warningf(format string, args ...interface{}) {
+	d.log(Warn, KindFormat, format, args)
+}
+
+func (d *decorator) Errorf(format string, args ...interface{}) {
+	d.log(Error, KindFormat, format, args)
+}
+
+func (d *decorator) Fatalf(format string, args ...interface{}) {
+	d.log(Fatal, KindFormat, format, args)
+}
+
+func (d *decorator) Panicf(format string, args ...interface{}) {
+	d.log(Panic, KindFormat, format, args)
+}
+
+func (d *decorator) Debug(args ...interface{}) {
+	d.log(Debug, KindPrint, "", args)
+}
+
+func (d *decorator) Info(args ...interface{}) {
+	d.log(Info, KindPrint, "", args)
+}
+
+func (d *decorator) Print(args ...interface{}) {
+	d.log(Info, KindPrint, "", args)
+}
+
+func (d *decorator) Warn(args ...interface{}) {
+	d.log(Warn, KindPrint, "", args)
+}
+
+func (d *decorator) Warning(args ...interface{}) {
+	d.log(Warn, KindPrint, "", args)
+}
+
+func (d *decorator) Error(args ...interface{}) {
+	d.log(Error, KindPrint, "", args)
+}
+
+func (d *decorator) Fatal(args ...interface{}) {
+	d.log(Fatal, KindPrint, "", args)
+}
+
+func (d *decorator) Panic(args ...interface{}) {
+	d.log(Panic, KindPrint, "", args)
+}
+
+func (d *decorator) Debugln(args ...interface{}) {
+	d.log(Debug, KindLine, "", args)
+}
+
+func (d *decorator) Infoln(args ...interface{}) {
+	d.log(Info, KindLine, "", args)
+}
+
+func (d *decorator) Println(args ...interface{}) {
+	d.log(Info, KindLine, "", args)
+}
+
+func (d *decorator) Warnln(args ...interface{}) {
+	d.log(Warn, KindLine, "", args)
+}
+
+func (d *decorator) Warningln(args ...interface{}) {
+	d.log(Warn, KindLine, "", args)
+}
+
+func (d *decorator) Errorln(args ...interface{}) {
+	d.log(Error, KindLine, "", args)
+}
+
+func (d *decorator) Fatalln(args ...interface{}) {
+	d.log(Fatal, KindLine, "", args)
+}
+
+func (d *decorator) Panicln(args ...interface{}) {
+	d.log(Panic, KindLine, "", args)
+}
+
+func (d *decorator) Trace(args ...interface{}) {
+	d.log(Trace, KindPrint, "", args)
+}
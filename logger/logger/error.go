@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const (
+	FieldError      = "error"
+	FieldErrorChain = "error_chain"
+	FieldStacktrace = "stacktrace"
+)
+
+type (
+	causer interface {
+		Cause() error
+	}
+)
+
+// ErrorFields convert error into structured fields: the message, the message of every wrapped error,
+// and the stack trace of the deepest error carrying one (e.g. created by github.com/pkg/errors)
+func ErrorFields(err error) map[string]interface{} {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		chain = make([]string, 0)
+		stack = ""
+	)
+
+	for e := err; e != nil; e = unwrap(e) {
+		if msg := e.Error(); len(chain) == 0 || chain[len(chain)-1] != msg {
+			chain = append(chain, msg)
+		}
+
+		if trace := stackTrace(e); trace != "" {
+			stack = trace
+		}
+	}
+
+	fields := map[string]interface{}{
+		FieldError:      err.Error(),
+		FieldErrorChain: chain,
+	}
+
+	if stack != "" {
+		fields[FieldStacktrace] = stack
+	}
+	return fields
+}
+
+func unwrap(err error) error {
+	if e := errors.Unwrap(err); e != nil {
+		return e
+	}
+
+	if c, ok := err.(causer); ok {
+		return c.Cause()
+	}
+	return nil
+}
+
+// stackTrace look up `StackTrace()` method by reflection to avoid depending on any errors package
+func stackTrace(err error) string {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ""
+	}
+
+	return fmt.Sprintf("%+v", method.Call(nil)[0].Interface())
+}
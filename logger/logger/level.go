@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	FieldModule = "module"
+)
+
+type (
+	// LevelSet hold the root level and the level of every module, shared by a logger and its sub-loggers
+	LevelSet struct {
+		mutex   sync.RWMutex
+		root    Level
+		modules map[string]Level
+	}
+)
+
+var (
+	severities = map[Level]int{
+		Trace: 0,
+		Debug: 1,
+		Info:  2,
+		Warn:  3,
+		Error: 4,
+		Fatal: 5,
+		Panic: 6,
+	}
+)
+
+// ParseLevel parse level name case-insensitively, WARNING is accepted as WARN
+func ParseLevel(name string) (Level, error) {
+	level := Level(strings.ToUpper(strings.TrimSpace(name)))
+	if level == "WARNING" {
+		level = Warn
+	}
+
+	if _, ok := severities[level]; !ok {
+		return "", fmt.Errorf("unknown log level %s", name)
+	}
+	return level, nil
+}
+
+// Enabled check whether entry with the given level is logged when l is the minimum level
+func (l Level) Enabled(level Level) bool {
+	return level.severity() >= l.severity()
+}
+
+func (l Level) severity() int {
+	if severity, ok := severities[l]; ok {
+		return severity
+	}
+	return severities[Info]
+}
+
+// NewLevelSet read root like ParseLevel, an unknown root level fall back to Info
+func NewLevelSet(root Level) *LevelSet {
+	root, err := ParseLevel(string(root))
+	if err != nil {
+		root = Info
+	}
+
+	return &LevelSet{
+		root:    root,
+		modules: make(map[string]Level),
+	}
+}
+
+// Get return the level of module, or the root level when module is empty or has no level
+func (s *LevelSet) Get(module string) Level {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if level, ok := s.modules[module]; ok && module != "" {
+		return level
+	}
+	return s.root
+}
+
+// Set change the level of module, or the root level when module is empty. The level is read like ParseLevel,
+// an unknown level is ignored
+func (s *LevelSet) Set(module string, level Level) {
+	level, err := ParseLevel(string(level))
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if module == "" {
+		s.root = level
+		return
+	}
+	s.modules[module] = level
+}
+
+// Reset make module follow the root level again
+func (s *LevelSet) Reset(module string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.modules, module)
+}
+
+func (s *LevelSet) Modules() map[string]Level {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	modules := make(map[string]Level, len(s.modules))
+	for module, level := range s.modules {
+		modules[module] = level
+	}
+	return modules
+}
+
+func (s *LevelSet) Enabled(module string, level Level) bool {
+	return s.Get(module).Enabled(level)
+}
+
+// ModuleName join parent module and name, e.g. `kafka.reader`
+func ModuleName(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
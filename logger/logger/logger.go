@@ -1,5 +1,9 @@
 package logger
 
+import (
+	"context"
+)
+
 type (
 	Logger interface {
 		Debugf(format string, args ...interface{})
@@ -28,5 +32,34 @@ type (
 		Panicln(args ...interface{})
 		Trace(args ...interface{})
 		WithFields(data map[string]interface{}) Logger
+		WithContext(ctx context.Context) Logger
+		WithError(err error) Logger
+
+		// SetLevel change the level at runtime, on sub-logger created by Module only the module level is changed.
+		// An unknown level is ignored, use ParseLevel to validate a level read from the user
+		SetLevel(level Level)
+		GetLevel() Level
+		// Module create sub-logger for a component, having its own level which follow the root level until it is set
+		Module(name string) Logger
+
+		// Sync flush the buffered entries, Close flush and release the output, call it during shutdown
+		Sync() error
+		Close() error
 	}
 )
+
+// MergeFields merge the given fields into a new map, later fields override the earlier one
+func MergeFields(fields ...map[string]interface{}) map[string]interface{} {
+	size := 0
+	for _, f := range fields {
+		size += len(f)
+	}
+
+	merged := make(map[string]interface{}, size)
+	for _, f := range fields {
+		for key, value := range f {
+			merged[key] = value
+		}
+	}
+	return merged
+}
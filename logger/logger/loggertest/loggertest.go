@@ -0,0 +1,61 @@
+// Package loggertest provide a logger.Logger for the tests of the packages taking a logger
+package loggertest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/neazossa/common-util-go/logger/logger"
+)
+
+type (
+	// Logger fail the test on every entry, for the cases where the tested code should not log
+	Logger struct {
+		t testing.TB
+	}
+)
+
+func New(t testing.TB) Logger {
+	return Logger{t: t}
+}
+
+func (l Logger) fail(msg string) {
+	l.t.Helper()
+	l.t.Errorf("unexpected log: %s", msg)
+}
+
+func (l Logger) Debugf(format string, args ...interface{})   { l.fail(fmt.Sprintf(format, args...)) }
+func (l Logger) Infof(format string, args ...interface{})    { l.fail(fmt.Sprintf(format, args...)) }
+func (l Logger) Printf(format string, args ...interface{})   { l.fail(fmt.Sprintf(format, args...)) }
+func (l Logger) Warnf(format string, args ...interface{})    { l.fail(fmt.Sprintf(format, args...)) }
+func (l Logger) Warningf(format string, args ...interface{}) { l.fail(fmt.Sprintf(format, args...)) }
+func (l Logger) Errorf(format string, args ...interface{})   { l.fail(fmt.Sprintf(format, args...)) }
+func (l Logger) Fatalf(format string, args ...interface{})   { l.fail(fmt.Sprintf(format, args...)) }
+func (l Logger) Panicf(format string, args ...interface{})   { l.fail(fmt.Sprintf(format, args...)) }
+func (l Logger) Debug(args ...interface{})                   { l.fail(fmt.Sprint(args...)) }
+func (l Logger) Info(args ...interface{})                    { l.fail(fmt.Sprint(args...)) }
+func (l Logger) Print(args ...interface{})                   { l.fail(fmt.Sprint(args...)) }
+func (l Logger) Warn(args ...interface{})                    { l.fail(fmt.Sprint(args...)) }
+func (l Logger) Warning(args ...interface{})                 { l.fail(fmt.Sprint(args...)) }
+func (l Logger) Error(args ...interface{})                   { l.fail(fmt.Sprint(args...)) }
+func (l Logger) Fatal(args ...interface{})                   { l.fail(fmt.Sprint(args...)) }
+func (l Logger) Panic(args ...interface{})                   { l.fail(fmt.Sprint(args...)) }
+func (l Logger) Trace(args ...interface{})                   { l.fail(fmt.Sprint(args...)) }
+func (l Logger) Debugln(args ...interface{})                 { l.fail(fmt.Sprintln(args...)) }
+func (l Logger) Infoln(args ...interface{})                  { l.fail(fmt.Sprintln(args...)) }
+func (l Logger) Println(args ...interface{})                 { l.fail(fmt.Sprintln(args...)) }
+func (l Logger) Warnln(args ...interface{})                  { l.fail(fmt.Sprintln(args...)) }
+func (l Logger) Warningln(args ...interface{})               { l.fail(fmt.Sprintln(args...)) }
+func (l Logger) Errorln(args ...interface{})                 { l.fail(fmt.Sprintln(args...)) }
+func (l Logger) Fatalln(args ...interface{})                 { l.fail(fmt.Sprintln(args...)) }
+func (l Logger) Panicln(args ...interface{})                 { l.fail(fmt.Sprintln(args...)) }
+
+func (l Logger) WithFields(data map[string]interface{}) logger.Logger { return l }
+func (l Logger) WithContext(ctx context.Context) logger.Logger        { return l }
+func (l Logger) WithError(err error) logger.Logger                    { return l }
+func (l Logger) Module(name string) logger.Logger                     { return l }
+func (l Logger) SetLevel(level logger.Level)                          {}
+func (l Logger) GetLevel() logger.Level                               { return logger.Trace }
+func (l Logger) Sync() error                                          { return nil }
+func (l Logger) Close() error                                         { return nil }
@@ -0,0 +1,340 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	MaskReplacement = "****"
+	MaskTag         = "log"
+	MaskTagValue    = "mask"
+
+	// maxMaskDepth stop the recursion into cyclic values, a deeper value is replaced by MaskReplacement since it
+	// could not be checked
+	maxMaskDepth = 10
+)
+
+type (
+	// Detector find sensitive values inside a string and mask every match
+	Detector struct {
+		Name    string
+		Pattern *regexp.Regexp
+		Mask    func(match string) string
+	}
+
+	MaskOption struct {
+		Detectors   []Detector // default DefaultDetectors, set an empty slice to mask by key and tag only
+		Keys        []string   // field names always masked, case insensitive, default DefaultMaskKeys
+		SkipMessage bool       // mask the fields only, leave the message untouched
+	}
+
+	// Masker mask sensitive data of the message and of the fields given to WithFields before they reach the formatter
+	Masker struct {
+		detectors   []Detector
+		keys        map[string]struct{}
+		skipMessage bool
+		sensitive   sync.Map // reflect.Type of the structs with their own rendering -> bool
+	}
+)
+
+var (
+	DetectorJWT = Detector{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		Mask: func(string) string {
+			return "eyJ" + MaskReplacement
+		},
+	}
+
+	DetectorBearer = Detector{
+		Name:    "bearer",
+		Pattern: regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`),
+		Mask: func(match string) string {
+			return strings.Fields(match)[0] + " " + MaskReplacement
+		},
+	}
+
+	DetectorCard = Detector{
+		Name:    "card",
+		Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Mask: func(match string) string {
+			if !luhn(match) {
+				return match
+			}
+			return maskDigits(match, 4)
+		},
+	}
+
+	DetectorEmail = Detector{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Mask: func(match string) string {
+			at := strings.LastIndex(match, "@")
+			return match[:1] + MaskReplacement + match[at:]
+		},
+	}
+
+	DetectorPhone = Detector{
+		Name:    "phone",
+		Pattern: regexp.MustCompile(`(?:\+\d{1,3}|\b0)[\s-]?\d{2,4}[\s-]?\d{3,4}[\s-]?\d{3,5}\b`),
+		Mask: func(match string) string {
+			return maskDigits(match, 4)
+		},
+	}
+
+	// - order matters, card number must be checked before phone number
+	DefaultDetectors = []Detector{DetectorJWT, DetectorBearer, DetectorCard, DetectorEmail, DetectorPhone}
+
+	DefaultMaskKeys = []string{"password", "token", "otp", "pin", "secret", "authorization", "access_token", "refresh_token"}
+)
+
+// NewMasking wrap logger with Masker
+func NewMasking(l Logger, option MaskOption) Logger {
+	return Decorate(l, NewMasker(option))
+}
+
+func NewMasker(option MaskOption) *Masker {
+	if option.Detectors == nil {
+		option.Detectors = DefaultDetectors
+	}
+
+	if option.Keys == nil {
+		option.Keys = DefaultMaskKeys
+	}
+
+	keys := make(map[string]struct{}, len(option.Keys))
+	for _, key := range option.Keys {
+		keys[strings.ToLower(key)] = struct{}{}
+	}
+
+	return &Masker{
+		detectors:   option.Detectors,
+		keys:        keys,
+		skipMessage: option.SkipMessage,
+	}
+}
+
+// Intercept replace the message with its masked rendering, the entry is never dropped
+func (m *Masker) Intercept(entry *Entry) bool {
+	if m.skipMessage || !entry.Enabled {
+		return true
+	}
+
+	entry.Args = []interface{}{m.String(entry.Message())}
+	entry.Kind = KindPrint
+	entry.Format = ""
+	return true
+}
+
+func (m *Masker) InterceptFields(fields map[string]interface{}) map[string]interface{} {
+	if fields == nil {
+		return nil
+	}
+
+	masked, _ := m.Value(fields).(map[string]interface{})
+	return masked
+}
+
+// String mask every match of the detectors
+func (m *Masker) String(s string) string {
+	for _, detector := range m.detectors {
+		s = detector.Pattern.ReplaceAllStringFunc(s, detector.Mask)
+	}
+	return s
+}
+
+// Value mask strings, fields named like Keys and struct fields tagged `log:"mask"`.
+// Maps and structs are returned as map[string]interface{} keyed by json name, slices as []interface{}.
+func (m *Masker) Value(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return m.mask(reflect.ValueOf(value), 0)
+}
+
+func (m *Masker) isKey(key string) bool {
+	_, ok := m.keys[strings.ToLower(key)]
+	return ok
+}
+
+func (m *Masker) mask(v reflect.Value, depth int) interface{} {
+	if depth > maxMaskDepth {
+		return MaskReplacement
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return m.String(v.String())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return m.mask(v.Elem(), depth+1)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+
+		masked := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if m.isKey(key) {
+				masked[key] = MaskReplacement
+				continue
+			}
+			masked[key] = m.mask(iter.Value(), depth+1)
+		}
+		return masked
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 || (v.Kind() == reflect.Slice && v.IsNil()) {
+			return v.Interface()
+		}
+
+		masked := make([]interface{}, v.Len())
+		for i := range masked {
+			masked[i] = m.mask(v.Index(i), depth+1)
+		}
+		return masked
+	case reflect.Struct:
+		// - time.Time and types with their own rendering are kept as is, unless their rendering could show a field
+		//   that must be masked
+		switch v.Interface().(type) {
+		case json.Marshaler, fmt.Stringer, error:
+			if !m.hasSensitiveFields(v.Type()) {
+				return v.Interface()
+			}
+		}
+
+		masked := make(map[string]interface{}, v.NumField())
+		m.maskStruct(v, masked, depth)
+		return masked
+	default:
+		return v.Interface()
+	}
+}
+
+func (m *Masker) maskStruct(v reflect.Value, masked map[string]interface{}, depth int) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// - embedded struct without json name is flattened like encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			m.maskStruct(v.Field(i), masked, depth+1)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if field.Tag.Get(MaskTag) == MaskTagValue || m.isKey(name) {
+			masked[name] = MaskReplacement
+			continue
+		}
+		masked[name] = m.mask(v.Field(i), depth+1)
+	}
+}
+
+// hasSensitiveFields tell whether the struct t has a field tagged `log:"mask"` or named like Keys, directly or in its
+// nested structs
+func (m *Masker) hasSensitiveFields(t reflect.Type) bool {
+	if sensitive, ok := m.sensitive.Load(t); ok {
+		return sensitive.(bool)
+	}
+
+	sensitive := m.sensitiveType(t, 0)
+	m.sensitive.Store(t, sensitive)
+	return sensitive
+}
+
+func (m *Masker) sensitiveType(t reflect.Type, depth int) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || depth > maxMaskDepth {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		if field.Tag.Get(MaskTag) == MaskTagValue || m.isKey(name) || m.sensitiveType(field.Type, depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskDigits replace every digit with * except the last keep digits
+func maskDigits(s string, keep int) string {
+	var (
+		digits = 0
+		masked = []byte(s)
+	)
+
+	for _, c := range masked {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+
+	for i, c := range masked {
+		if digits <= keep {
+			break
+		}
+		if c >= '0' && c <= '9' {
+			masked[i] = '*'
+			digits--
+		}
+	}
+	return string(masked)
+}
+
+func luhn(s string) bool {
+	var (
+		sum    = 0
+		double = false
+	)
+
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type (
+	account struct {
+		ID       string `json:"id"`
+		Password string
+		Card     string `json:"card" log:"mask"`
+	}
+
+	// stringerAccount render itself, the rendering would print the card
+	stringerAccount struct {
+		Account account `json:"account"`
+	}
+
+	marshalerOrder struct {
+		ID    string  `json:"id"`
+		Buyer account `json:"buyer"`
+	}
+
+	errorAccount struct {
+		ID  string `json:"id"`
+		Pin string `json:"pin"`
+	}
+
+	version struct {
+		Major int
+	}
+)
+
+func (a stringerAccount) String() string { return a.Account.ID + " " + a.Account.Card }
+
+func (o marshalerOrder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"id": o.ID, "card": o.Buyer.Card})
+}
+
+func (e errorAccount) Error() string { return "invalid pin " + e.Pin }
+
+func (v version) String() string { return "v1" }
+
+func TestMaskRenderedStruct(t *testing.T) {
+	masker := NewMasker(MaskOption{})
+	now := time.Now()
+
+	for _, tc := range []struct {
+		name  string
+		value interface{}
+		want  map[string]interface{}
+	}{
+		{
+			name:  "stringer with a masked field",
+			value: stringerAccount{account{ID: "1", Password: "secret", Card: "4111"}},
+			want: map[string]interface{}{"account": map[string]interface{}{
+				"id": "1", "Password": MaskReplacement, "card": MaskReplacement,
+			}},
+		},
+		{
+			name:  "marshaler with a masked nested field",
+			value: marshalerOrder{ID: "2", Buyer: account{ID: "1", Card: "4111"}},
+			want: map[string]interface{}{"id": "2", "buyer": map[string]interface{}{
+				"id": "1", "Password": MaskReplacement, "card": MaskReplacement,
+			}},
+		},
+		{
+			name:  "error with a field named like a key",
+			value: &errorAccount{ID: "1", Pin: "1234"},
+			want:  map[string]interface{}{"id": "1", "pin": MaskReplacement},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			masked, ok := masker.Value(tc.value).(map[string]interface{})
+			if !ok {
+				t.Fatalf("masked = %#v, want the fields walked", masker.Value(tc.value))
+			}
+			got, _ := json.Marshal(masked)
+			want, _ := json.Marshal(tc.want)
+			if string(got) != string(want) {
+				t.Errorf("masked = %s, want %s", got, want)
+			}
+		})
+	}
+
+	if masked := masker.Value(now); masked != now {
+		t.Errorf("time.Time masked into %#v, want it kept", masked)
+	}
+	if masked := masker.Value(version{Major: 1}); masked != (version{Major: 1}) {
+		t.Errorf("stringer without sensitive field masked into %#v, want it kept", masked)
+	}
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"time"
+)
+
+type (
+	Level     string
+	Formatter string
+
+	Option struct {
+		Level        Level
+		LogFilePath  string
+		FileName     string // default DefaultFileName
+		Formatter    Formatter
+		MaxSize      int           // megabytes, rotate when the file would exceed it, 0 disable size rotation
+		MaxBackups   int           // rotated files to retain, 0 retain all
+		MaxAge       time.Duration // remove rotated files older than it, 0 retain all
+		RotationTime time.Duration // default 24 hours
+		Compress     bool          // gzip rotated files
+		FileOnly     bool          // write to the file only instead of teeing to stdout
+		Async        *AsyncOption  // write through a bounded queue instead of blocking the caller
+	}
+)
+
+const (
+	Trace Level = "TRACE"
+	Debug Level = "DEBUG"
+	Info  Level = "INFO"
+	Warn  Level = "WARN"
+	Error Level = "ERROR"
+	Fatal Level = "FATAL"
+	Panic Level = "PANIC"
+
+	JSONFormatter Formatter = "JSON"
+	TextFormatter Formatter = "TEXT"
+
+	DefaultFileName     = "RequestResponseDump.log"
+	DefaultRotationTime = 24 * time.Hour
+)
@@ -0,0 +1,317 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	megabyte          = 1024 * 1024
+	compressSuffix    = ".gz"
+	rotationTimestamp = "20060102150405"
+)
+
+type (
+	// RotateWriter write into LogFilePath/FileName, the file is rotated when it would exceed MaxSize
+	// or when the RotationTime period is over. Rotated files are named FileName.<timestamp>[.gz]
+	RotateWriter struct {
+		mutex    sync.Mutex
+		mill     sync.Mutex // compress and remove the rotated files one rotation at a time
+		wg       sync.WaitGroup
+		dir      string
+		name     string
+		maxSize  int64
+		backups  int
+		maxAge   time.Duration
+		period   time.Duration
+		compress bool
+		file     *os.File
+		size     int64
+		openedAt time.Time
+	}
+
+	teeWriter struct {
+		io.Writer
+		closer io.Closer
+	}
+
+	nopCloser struct {
+		io.Writer
+	}
+)
+
+// NewWriter create the output of logger from option, stdout when LogFilePath is empty,
+// otherwise the rotated file teed to stdout unless FileOnly is set. Async option wrap the output with AsyncWriter.
+func NewWriter(option *Option) (io.WriteCloser, error) {
+	writer, err := newOutput(option)
+	if err != nil {
+		return nil, err
+	}
+
+	if option.Async != nil {
+		return NewAsyncWriter(writer, *option.Async), nil
+	}
+	return writer, nil
+}
+
+func newOutput(option *Option) (io.WriteCloser, error) {
+	if option.LogFilePath == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	file, err := NewRotateWriter(option)
+	if err != nil {
+		return nil, err
+	}
+
+	if option.FileOnly {
+		return file, nil
+	}
+	return teeWriter{io.MultiWriter(os.Stdout, file), file}, nil
+}
+
+func NewRotateWriter(option *Option) (*RotateWriter, error) {
+	w := &RotateWriter{
+		dir:      option.LogFilePath,
+		name:     option.FileName,
+		maxSize:  int64(option.MaxSize) * megabyte,
+		backups:  option.MaxBackups,
+		maxAge:   option.MaxAge,
+		period:   option.RotationTime,
+		compress: option.Compress,
+	}
+
+	if w.name == "" {
+		w.name = DefaultFileName
+	}
+
+	if w.period <= 0 {
+		w.period = DefaultRotationTime
+	}
+
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate force the current file to be rotated
+func (w *RotateWriter) Rotate() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.rotate()
+}
+
+func (w *RotateWriter) Sync() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close close the current file and wait for the pending compression
+func (w *RotateWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+
+	w.wg.Wait()
+	return err
+}
+
+func (w *RotateWriter) path() string {
+	return filepath.Join(w.dir, w.name)
+}
+
+func (w *RotateWriter) shouldRotate(size int64) bool {
+	if w.maxSize > 0 && w.size > 0 && w.size+size > w.maxSize {
+		return true
+	}
+	return !time.Now().Truncate(w.period).Equal(w.openedAt.Truncate(w.period))
+}
+
+func (w *RotateWriter) open() error {
+	file, err := os.OpenFile(w.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	w.file = file
+	w.size = info.Size()
+	w.openedAt = info.ModTime()
+	if w.size == 0 {
+		w.openedAt = time.Now()
+	}
+	return nil
+}
+
+func (w *RotateWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+
+	backup := w.backupName()
+	if err := os.Rename(w.path(), backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.mill.Lock()
+		defer w.mill.Unlock()
+
+		if w.compress {
+			_ = compressFile(backup)
+		}
+		w.removeBackups()
+	}()
+	return nil
+}
+
+func (w *RotateWriter) backupName() string {
+	name := w.path() + "." + time.Now().Format(rotationTimestamp)
+	backup := name
+	for i := 1; exists(backup) || exists(backup+compressSuffix); i++ {
+		backup = fmt.Sprintf("%s.%d", name, i)
+	}
+	return backup
+}
+
+// removeBackups remove rotated files exceeding MaxBackups or older than MaxAge
+func (w *RotateWriter) removeBackups() {
+	if w.backups <= 0 && w.maxAge <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return
+	}
+
+	backups := make([]os.FileInfo, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), w.name+".") {
+			continue
+		}
+
+		if info, err := entry.Info(); err == nil {
+			backups = append(backups, info)
+		}
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime().After(backups[j].ModTime())
+	})
+
+	for i, backup := range backups {
+		expired := w.maxAge > 0 && time.Since(backup.ModTime()) > w.maxAge
+		if (w.backups > 0 && i >= w.backups) || expired {
+			_ = os.Remove(filepath.Join(w.dir, backup.Name()))
+		}
+	}
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		_ = gz.Close()
+		_ = dst.Close()
+		_ = os.Remove(name + compressSuffix)
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+func (t teeWriter) Sync() error {
+	return syncWriter(t.closer.(io.Writer))
+}
+
+func (t teeWriter) Close() error {
+	return t.closer.Close()
+}
+
+// Sync is a no-op, stdout is not buffered and syncing a terminal or pipe fail
+func (nopCloser) Sync() error {
+	return nil
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSampleInterval   = time.Second
+	DefaultSampleFirst      = 100
+	DefaultSampleThereafter = 100
+)
+
+type (
+	// SamplerOption log the First entries of a message template per Interval, then every Thereafter-th entry.
+	// Thereafter 0 drop every entry after the First.
+	SamplerOption struct {
+		Interval   time.Duration
+		First      int
+		Thereafter int
+	}
+
+	sampler struct {
+		option   SamplerOption
+		logger   Logger
+		mutex    sync.Mutex
+		started  time.Time
+		counters map[string]int
+		sites    map[string]*siteCounter
+	}
+
+	// siteCounter count the entries dropped at a single call site during the interval
+	siteCounter struct {
+		level    Level
+		template string
+		total    int
+		dropped  int
+	}
+)
+
+// NewSampler wrap logger to limit noisy log sites. Every interval a summary line is written for each call site
+// having dropped entries, it is emitted lazily by the first entry of the next interval.
+func NewSampler(l Logger, option SamplerOption) Logger {
+	if option.Interval <= 0 {
+		option.Interval = DefaultSampleInterval
+	}
+
+	if option.First <= 0 {
+		option.First = DefaultSampleFirst
+	}
+
+	if option.Thereafter < 0 {
+		option.Thereafter = 0
+	}
+
+	return Decorate(l, &sampler{
+		option:   option,
+		logger:   l,
+		started:  time.Now(),
+		counters: make(map[string]int),
+		sites:    make(map[string]*siteCounter),
+	})
+}
+
+func (s *sampler) Intercept(entry *Entry) bool {
+	if !entry.Enabled {
+		return false
+	}
+
+	var (
+		key     = string(entry.Level) + ":" + entry.Template()
+		summary map[string]*siteCounter
+	)
+
+	s.mutex.Lock()
+	if now := time.Now(); now.Sub(s.started) >= s.option.Interval {
+		summary = s.sites
+		s.started = now
+		s.counters = make(map[string]int)
+		s.sites = make(map[string]*siteCounter)
+	}
+
+	s.counters[key]++
+	count := s.counters[key]
+	sampled := count <= s.option.First ||
+		(s.option.Thereafter > 0 && (count-s.option.First)%s.option.Thereafter == 0)
+
+	site, ok := s.sites[entry.Caller]
+	if !ok {
+		site = &siteCounter{level: entry.Level, template: entry.Template()}
+		s.sites[entry.Caller] = site
+	}
+	site.total++
+	if !sampled {
+		site.dropped++
+	}
+	s.mutex.Unlock()
+
+	s.summarize(summary)
+	return sampled
+}
+
+func (s *sampler) summarize(sites map[string]*siteCounter) {
+	for caller, site := range sites {
+		if site.dropped == 0 {
+			continue
+		}
+
+		s.logger.WithFields(map[string]interface{}{
+			"sampled_caller":   caller,
+			"sampled_level":    site.level,
+			"sampled_template": site.template,
+			"sampled_total":    site.total,
+			"sampled_dropped":  site.dropped,
+		}).Infof("log sampling dropped %d of %d entries at %s", site.dropped, site.total, caller)
+	}
+}
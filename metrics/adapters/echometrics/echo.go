@@ -0,0 +1,89 @@
+package echometrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/neazossa/common-util-go/metrics/metrics"
+)
+
+const (
+	// RouteNotFound label the requests matching no route pattern, the raw path would make a series per path
+	RouteNotFound = "not_found"
+)
+
+type (
+	// Option configure Middleware
+	// - Skipper return true for requests that must not be counted, default skip /metrics and swagger
+	Option struct {
+		metrics.ServerOption
+		Skipper func(c echo.Context) bool
+	}
+)
+
+// Middleware record the RED metrics of the http_server subsystem labelled by method, echo route such as
+// /users/:id and status code, the same series as metrics.HTTPMiddleware
+func Middleware(m metrics.Metrics, option Option) echo.MiddlewareFunc {
+	if option.Skipper == nil {
+		option.Skipper = defaultSkipper
+	}
+
+	server := metrics.NewServerMetrics(m, metrics.SubsystemHTTPServer, option.ServerOption,
+		metrics.LabelMethod, metrics.LabelRoute, metrics.LabelCode)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			if option.Skipper(c) {
+				return next(c)
+			}
+
+			finish := server.Start()
+			defer func() {
+				// - a panic not recovered by the handler is counted as 500, then panic again for echo
+				rec := recover()
+
+				status := c.Response().Status
+				if rec != nil {
+					status = http.StatusInternalServerError
+				} else if err != nil && !c.Response().Committed {
+					status = errorStatus(err)
+				}
+
+				finish(c.Request().Method, route(c, err), strconv.Itoa(status))
+
+				if rec != nil {
+					panic(rec)
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// Handler serve the metrics on echo, e.g. e.GET("/metrics", echometrics.Handler(m))
+func Handler(m metrics.Metrics) echo.HandlerFunc {
+	return echo.WrapHandler(m.Handler())
+}
+
+// errorStatus return the status echo will respond for err
+func errorStatus(err error) int {
+	if he, ok := err.(*echo.HTTPError); ok {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// route return the echo route, RouteNotFound when no route matched since echo keep the raw path then
+func route(c echo.Context, err error) string {
+	if path := c.Path(); path != "" && err != echo.ErrNotFound {
+		return path
+	}
+	return RouteNotFound
+}
+
+func defaultSkipper(c echo.Context) bool {
+	path := c.Request().URL.Path
+	return path == "/metrics" || strings.Contains(path, "swagger")
+}
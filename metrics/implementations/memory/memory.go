@@ -0,0 +1,213 @@
+package memory
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/neazossa/common-util-go/metrics/metrics"
+)
+
+const (
+	KindCounter   = "counter"
+	KindGauge     = "gauge"
+	KindHistogram = "histogram"
+
+	labelSeparator = "\xff"
+)
+
+type (
+	// Memory keep the metrics in memory, to assert on metrics in tests
+	Memory struct {
+		mutex   *sync.RWMutex
+		metrics map[string]*metric
+	}
+
+	metric struct {
+		opts         metrics.Opts
+		kind         string
+		values       map[string]float64
+		observations map[string][]float64
+	}
+
+	// series is the metric handed out by Counter, Gauge and Histogram
+	series struct {
+		memory *Memory
+		metric *metric
+	}
+)
+
+func NewMemory() *Memory {
+	return &Memory{
+		mutex:   &sync.RWMutex{},
+		metrics: make(map[string]*metric),
+	}
+}
+
+func (m *Memory) Counter(opts metrics.Opts) metrics.Counter {
+	return m.series(opts, KindCounter)
+}
+
+func (m *Memory) Gauge(opts metrics.Opts) metrics.Gauge {
+	return m.series(opts, KindGauge)
+}
+
+func (m *Memory) Histogram(opts metrics.Opts) metrics.Histogram {
+	return m.series(opts, KindHistogram)
+}
+
+func (m *Memory) series(opts metrics.Opts, kind string) *series {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	name := opts.FullName()
+	mt, ok := m.metrics[name]
+	if !ok {
+		mt = &metric{
+			opts:         opts,
+			kind:         kind,
+			values:       make(map[string]float64),
+			observations: make(map[string][]float64),
+		}
+		m.metrics[name] = mt
+	}
+	return &series{memory: m, metric: mt}
+}
+
+// Handler write every series as `name{label="value"} value`, histograms as their _count and _sum
+func (m *Memory) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, line := range m.lines() {
+			fmt.Fprintln(w, line)
+		}
+	})
+}
+
+func (m *Memory) lines() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var lines []string
+	for name, mt := range m.metrics {
+		for key, value := range mt.values {
+			lines = append(lines, fmt.Sprintf("%s%s %v", name, mt.labels(key), value))
+		}
+		for key, observations := range mt.observations {
+			sum := 0.0
+			for _, value := range observations {
+				sum += value
+			}
+			lines = append(lines,
+				fmt.Sprintf("%s_count%s %d", name, mt.labels(key), len(observations)),
+				fmt.Sprintf("%s_sum%s %v", name, mt.labels(key), sum),
+			)
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// Value return the value of the counter or gauge named by its full name, 0 when nothing was recorded
+func (m *Memory) Value(name string, labels ...string) float64 {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if mt, ok := m.metrics[name]; ok {
+		return mt.values[labelKey(labels)]
+	}
+	return 0
+}
+
+// Observations return the values observed by the histogram named by its full name, in observation order
+func (m *Memory) Observations(name string, labels ...string) []float64 {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if mt, ok := m.metrics[name]; ok {
+		return append([]float64(nil), mt.observations[labelKey(labels)]...)
+	}
+	return nil
+}
+
+// Kind return the kind of the metric named by its full name, empty when it was never created
+func (m *Memory) Kind(name string) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if mt, ok := m.metrics[name]; ok {
+		return mt.kind
+	}
+	return ""
+}
+
+// Reset forget every recorded value, the created metrics keep working
+func (m *Memory) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, mt := range m.metrics {
+		mt.values = make(map[string]float64)
+		mt.observations = make(map[string][]float64)
+	}
+}
+
+func (s *series) Inc(labels ...string) {
+	s.Add(1, labels...)
+}
+
+func (s *series) Dec(labels ...string) {
+	s.Add(-1, labels...)
+}
+
+// Add drop negative value for counters, a counter never goes down
+func (s *series) Add(value float64, labels ...string) {
+	if s.metric.kind == KindCounter && value < 0 {
+		return
+	}
+	s.update(labels, func(key string) {
+		s.metric.values[key] += value
+	})
+}
+
+func (s *series) Set(value float64, labels ...string) {
+	s.update(labels, func(key string) {
+		s.metric.values[key] = value
+	})
+}
+
+func (s *series) Observe(value float64, labels ...string) {
+	s.update(labels, func(key string) {
+		s.metric.observations[key] = append(s.metric.observations[key], value)
+	})
+}
+
+// update call f with the key of the label values, values not matching the labels are dropped like prometheus
+func (s *series) update(labels []string, f func(key string)) {
+	if len(labels) != len(s.metric.opts.Labels) {
+		return
+	}
+
+	s.memory.mutex.Lock()
+	defer s.memory.mutex.Unlock()
+	f(labelKey(labels))
+}
+
+func (mt *metric) labels(key string) string {
+	if len(mt.opts.Labels) == 0 {
+		return ""
+	}
+
+	values := strings.Split(key, labelSeparator)
+	pairs := make([]string, len(mt.opts.Labels))
+	for i, label := range mt.opts.Labels {
+		pairs[i] = fmt.Sprintf("%s=%q", label, values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func labelKey(labels []string) string {
+	return strings.Join(labels, labelSeparator)
+}
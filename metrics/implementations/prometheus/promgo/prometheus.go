@@ -0,0 +1,169 @@
+package promgo
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/neazossa/common-util-go/metrics/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type (
+	// Option configure NewPrometheusMetrics
+	// - Registerer default prometheus.DefaultRegisterer, give prometheus.NewRegistry() to keep the metrics apart
+	// - ConstLabels are added to every metric, such as the service name
+	Option struct {
+		Registerer  prometheus.Registerer
+		ConstLabels map[string]string
+	}
+
+	promMetrics struct {
+		option     Option
+		mutex      sync.Mutex
+		collectors map[string]prometheus.Collector
+	}
+
+	counter struct {
+		vec *prometheus.CounterVec
+	}
+
+	gauge struct {
+		vec *prometheus.GaugeVec
+	}
+
+	histogram struct {
+		vec *prometheus.HistogramVec
+	}
+)
+
+// NewPrometheusMetrics register the created metrics to Option.Registerer, Handler serve them in prometheus format.
+// Registering an invalid metric, or a name already used with other labels, panic like prometheus.MustRegister.
+func NewPrometheusMetrics(option Option) metrics.Metrics {
+	if option.Registerer == nil {
+		option.Registerer = prometheus.DefaultRegisterer
+	}
+
+	return &promMetrics{
+		option:     option,
+		collectors: make(map[string]prometheus.Collector),
+	}
+}
+
+func (p *promMetrics) Counter(opts metrics.Opts) metrics.Counter {
+	vec := p.register(opts, func() prometheus.Collector {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        opts.Name,
+			Help:        opts.Help,
+			ConstLabels: p.option.ConstLabels,
+		}, opts.Labels)
+	})
+	return &counter{vec: vec.(*prometheus.CounterVec)}
+}
+
+func (p *promMetrics) Gauge(opts metrics.Opts) metrics.Gauge {
+	vec := p.register(opts, func() prometheus.Collector {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        opts.Name,
+			Help:        opts.Help,
+			ConstLabels: p.option.ConstLabels,
+		}, opts.Labels)
+	})
+	return &gauge{vec: vec.(*prometheus.GaugeVec)}
+}
+
+func (p *promMetrics) Histogram(opts metrics.Opts) metrics.Histogram {
+	if len(opts.Buckets) == 0 {
+		opts.Buckets = metrics.DefBuckets
+	}
+
+	vec := p.register(opts, func() prometheus.Collector {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        opts.Name,
+			Help:        opts.Help,
+			ConstLabels: p.option.ConstLabels,
+			Buckets:     opts.Buckets,
+		}, opts.Labels)
+	})
+	return &histogram{vec: vec.(*prometheus.HistogramVec)}
+}
+
+// Handler expose the metrics of the registerer, or of the default gatherer when the registerer can not gather
+func (p *promMetrics) Handler() http.Handler {
+	if gatherer, ok := p.option.Registerer.(prometheus.Gatherer); ok {
+		return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+	}
+	return promhttp.Handler()
+}
+
+// register return the collector registered under the name of opts, create and register it otherwise.
+// A collector registered by other code with the same description is reused.
+func (p *promMetrics) register(opts metrics.Opts, create func() prometheus.Collector) prometheus.Collector {
+	name := opts.FullName()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if collector, ok := p.collectors[name]; ok {
+		return collector
+	}
+
+	collector := create()
+	if err := p.option.Registerer.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &registered) {
+			panic(err)
+		}
+		collector = registered.ExistingCollector
+	}
+
+	p.collectors[name] = collector
+	return collector
+}
+
+func (c *counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add drop negative value, a counter never goes down
+func (c *counter) Add(value float64, labels ...string) {
+	if value < 0 {
+		return
+	}
+	if metric, err := c.vec.GetMetricWithLabelValues(labels...); err == nil {
+		metric.Add(value)
+	}
+}
+
+func (g *gauge) Set(value float64, labels ...string) {
+	if metric, err := g.vec.GetMetricWithLabelValues(labels...); err == nil {
+		metric.Set(value)
+	}
+}
+
+func (g *gauge) Add(value float64, labels ...string) {
+	if metric, err := g.vec.GetMetricWithLabelValues(labels...); err == nil {
+		metric.Add(value)
+	}
+}
+
+func (g *gauge) Inc(labels ...string) {
+	g.Add(1, labels...)
+}
+
+func (g *gauge) Dec(labels ...string) {
+	g.Add(-1, labels...)
+}
+
+func (h *histogram) Observe(value float64, labels ...string) {
+	if metric, err := h.vec.GetMetricWithLabelValues(labels...); err == nil {
+		metric.Observe(value)
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	SubsystemHTTPClient = "http_client"
+
+	LabelHost        = "host"
+	LabelStatusClass = "status_class"
+	LabelPhase       = "phase"
+
+	// StatusClassError label the requests failing before a response, such as timeout or refused connection
+	StatusClassError = "error"
+
+	PhaseDNS     = "dns"
+	PhaseConnect = "connect"
+	PhaseTLS     = "tls"
+	PhaseTTFB    = "ttfb"
+)
+
+type (
+	// ClientOption configure the metrics of a client
+	// - Buckets of the duration histograms in seconds, default DefBuckets
+	ClientOption struct {
+		Namespace string
+		Buckets   []float64
+	}
+
+	// HTTPClientMetrics record the outgoing requests of an http client per host and route:
+	// - http_client_requests_total counter labelled by host, route, method and status class such as 2xx
+	// - http_client_request_duration_seconds histogram labelled by host, route and method
+	// - http_client_request_phase_duration_seconds histogram of dns, connect, tls and ttfb labelled by host, route
+	//   and phase
+	HTTPClientMetrics struct {
+		requests Counter
+		duration Histogram
+		phases   Histogram
+	}
+)
+
+// NewHTTPClientMetrics create the metrics of the http_client subsystem, the clients sharing m share the series
+func NewHTTPClientMetrics(m Metrics, option ClientOption) *HTTPClientMetrics {
+	return &HTTPClientMetrics{
+		requests: m.Counter(Opts{
+			Namespace: option.Namespace,
+			Subsystem: SubsystemHTTPClient,
+			Name:      "requests_total",
+			Help:      "Total of outgoing http requests per host and route.",
+			Labels:    []string{LabelHost, LabelRoute, LabelMethod, LabelStatusClass},
+		}),
+		duration: m.Histogram(Opts{
+			Namespace: option.Namespace,
+			Subsystem: SubsystemHTTPClient,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of outgoing http requests per host and route.",
+			Labels:    []string{LabelHost, LabelRoute, LabelMethod},
+			Buckets:   option.Buckets,
+		}),
+		phases: m.Histogram(Opts{
+			Namespace: option.Namespace,
+			Subsystem: SubsystemHTTPClient,
+			Name:      "request_phase_duration_seconds",
+			Help:      "DNS lookup, connect, TLS handshake and time to first byte of outgoing http requests.",
+			Labels:    []string{LabelHost, LabelRoute, LabelPhase},
+			Buckets:   option.Buckets,
+		}),
+	}
+}
+
+// Observe record a request answered with status, 0 when it failed before a response which is counted as
+// StatusClassError without latency
+func (c *HTTPClientMetrics) Observe(method, host, route string, status int, duration time.Duration) {
+	c.requests.Inc(host, route, method, StatusClass(status))
+	if status > 0 {
+		c.duration.Observe(duration.Seconds(), host, route, method)
+	}
+}
+
+// ObservePhase record a phase of a request, a zero duration such as dns of a reused connection is dropped
+func (c *HTTPClientMetrics) ObservePhase(host, route, phase string, duration time.Duration) {
+	if duration > 0 {
+		c.phases.Observe(duration.Seconds(), host, route, phase)
+	}
+}
+
+// StatusClass return the class of status such as 2xx, StatusClassError when there is no status
+func StatusClass(status int) string {
+	if status <= 0 {
+		return StatusClassError
+	}
+	return fmt.Sprintf("%dxx", status/100)
+}
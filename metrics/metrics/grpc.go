@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	GRPCTypeUnary        = "unary"
+	GRPCTypeClientStream = "client_stream"
+	GRPCTypeServerStream = "server_stream"
+	GRPCTypeBidiStream   = "bidi_stream"
+)
+
+// GRPCServerMetrics return the unary and stream interceptors recording the RED metrics of the grpc_server subsystem,
+// labelled by call type, full method and status code. Chain them before the recovery interceptors, so a recovered
+// panic is counted as Internal.
+func GRPCServerMetrics(m Metrics, option ServerOption) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	server := NewServerMetrics(m, SubsystemGRPCServer, option, LabelType, LabelMethod, LabelCode)
+
+	unary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		finish := server.Start()
+		defer func() {
+			rec := recover()
+			finish(GRPCTypeUnary, info.FullMethod, grpcCode(err, rec))
+			if rec != nil {
+				panic(rec)
+			}
+		}()
+		return handler(ctx, req)
+	}
+
+	stream := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		finish := server.Start()
+		defer func() {
+			rec := recover()
+			finish(streamType(info), info.FullMethod, grpcCode(err, rec))
+			if rec != nil {
+				panic(rec)
+			}
+		}()
+		return handler(srv, ss)
+	}
+
+	return unary, stream
+}
+
+// grpcCode return the code of err, a panic is counted as Internal
+func grpcCode(err error, rec interface{}) string {
+	if rec != nil {
+		return codes.Internal.String()
+	}
+	return status.Code(err).String()
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return GRPCTypeBidiStream
+	case info.IsClientStream:
+		return GRPCTypeClientStream
+	default:
+		return GRPCTypeServerStream
+	}
+}
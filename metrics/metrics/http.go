@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	LabelMethod = "method"
+	LabelRoute  = "route"
+	LabelCode   = "code"
+	LabelType   = "type"
+
+	// RouteUnmatched label the requests without route pattern, such as 404, the raw path would make a series per path
+	RouteUnmatched = "unmatched"
+)
+
+type (
+	// HTTPOption configure HTTPMiddleware
+	// - Route return the route pattern of the request such as /users/{id}, called after the handler. The requests
+	//   without pattern, or all of them when it is nil, are labelled RouteUnmatched
+	// - Skipper return true for requests that must not be counted, such as health check or /metrics itself
+	HTTPOption struct {
+		ServerOption
+		Route   func(r *http.Request) string
+		Skipper func(r *http.Request) bool
+	}
+
+	// statusWriter keep the status code written by the handler
+	statusWriter struct {
+		http.ResponseWriter
+		status int
+	}
+)
+
+// HTTPMiddleware record the RED metrics of the http_server subsystem labelled by method, route and status code
+func HTTPMiddleware(m Metrics, option HTTPOption) func(http.Handler) http.Handler {
+	server := NewServerMetrics(m, SubsystemHTTPServer, option.ServerOption, LabelMethod, LabelRoute, LabelCode)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if option.Skipper != nil && option.Skipper(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			finish := server.Start()
+			writer := &statusWriter{ResponseWriter: w}
+
+			defer func() {
+				// - a panic not recovered by the handler is counted as 500, then panic again for the server
+				rec := recover()
+				if rec != nil {
+					writer.status = http.StatusInternalServerError
+				}
+
+				route := RouteUnmatched
+				if option.Route != nil {
+					if pattern := option.Route(r); pattern != "" {
+						route = pattern
+					}
+				}
+
+				status := writer.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				finish(r.Method, route, strconv.Itoa(status))
+
+				if rec != nil {
+					panic(rec)
+				}
+			}()
+			next.ServeHTTP(writer, r)
+		})
+	}
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush keep streaming responses working through the wrapped writer
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap let http.ResponseController reach the wrapped writer
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+)
+
+type (
+	// Metrics create the counters, gauges and histograms of a service. Asking twice for the same name return
+	// the metric created first, so the middleware of several servers can share them.
+	Metrics interface {
+		Counter(opts Opts) Counter
+		Gauge(opts Opts) Gauge
+		Histogram(opts Opts) Histogram
+
+		// - Handler expose the collected metrics, usually served on /metrics
+		Handler() http.Handler
+	}
+
+	// Counter only goes up, such as the number of handled requests.
+	// The label values are given in the order of Opts.Labels, values not matching the labels are dropped.
+	Counter interface {
+		Inc(labels ...string)
+		Add(value float64, labels ...string)
+	}
+
+	// Gauge goes up and down, such as the number of requests in flight
+	Gauge interface {
+		Set(value float64, labels ...string)
+		Add(value float64, labels ...string)
+		Inc(labels ...string)
+		Dec(labels ...string)
+	}
+
+	// Histogram count the observed values in buckets, such as the latency in seconds
+	Histogram interface {
+		Observe(value float64, labels ...string)
+	}
+
+	// Opts describe a metric, the full name is namespace_subsystem_name
+	// - Buckets is used by histograms only, default DefBuckets
+	Opts struct {
+		Namespace string
+		Subsystem string
+		Name      string
+		Help      string
+		Labels    []string
+		Buckets   []float64
+	}
+)
+
+var (
+	// DefBuckets suit request latency in seconds, from 5ms to 10s like prometheus
+	DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+)
+
+// FullName join the non empty namespace, subsystem and name with underscore
+func (o Opts) FullName() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{o.Namespace, o.Subsystem, o.Name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "_")
+}
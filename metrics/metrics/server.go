@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"time"
+)
+
+const (
+	SubsystemHTTPServer = "http_server"
+	SubsystemGRPCServer = "grpc_server"
+)
+
+type (
+	// ServerOption configure the RED metrics of a server
+	// - Buckets of the duration histogram in seconds, default DefBuckets
+	ServerOption struct {
+		Namespace string
+		Buckets   []float64
+	}
+
+	// ServerMetrics record the rate, errors and duration of the requests of a server, shared by the http, echo
+	// and grpc middleware:
+	// - <subsystem>_requests_total counter, the errors are told by the code label
+	// - <subsystem>_request_duration_seconds histogram
+	// - <subsystem>_requests_in_flight gauge
+	ServerMetrics struct {
+		requests Counter
+		duration Histogram
+		inFlight Gauge
+	}
+)
+
+// NewServerMetrics create the RED metrics of subsystem, labels are the label names given to the func returned by Start
+func NewServerMetrics(m Metrics, subsystem string, option ServerOption, labels ...string) *ServerMetrics {
+	return &ServerMetrics{
+		requests: m.Counter(Opts{
+			Namespace: option.Namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Total of handled requests.",
+			Labels:    labels,
+		}),
+		duration: m.Histogram(Opts{
+			Namespace: option.Namespace,
+			Subsystem: subsystem,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of handled requests.",
+			Labels:    labels,
+			Buckets:   option.Buckets,
+		}),
+		inFlight: m.Gauge(Opts{
+			Namespace: option.Namespace,
+			Subsystem: subsystem,
+			Name:      "requests_in_flight",
+			Help:      "Requests being handled.",
+		}),
+	}
+}
+
+// Start count the request in flight, the returned func record it once handled with the label values
+func (s *ServerMetrics) Start() func(labels ...string) {
+	start := time.Now()
+	s.inFlight.Inc()
+
+	return func(labels ...string) {
+		s.inFlight.Dec()
+		s.requests.Inc(labels...)
+		s.duration.Observe(time.Since(start).Seconds(), labels...)
+	}
+}
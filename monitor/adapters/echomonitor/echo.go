@@ -0,0 +1,101 @@
+package echomonitor
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/neazossa/common-util-go/logger/logger"
+	"github.com/neazossa/common-util-go/monitor/monitor"
+	"github.com/neazossa/common-util-go/shared/requestctx"
+)
+
+const (
+	OperationEcho = "echo"
+)
+
+type (
+	// Option configure the panic handling of Middleware
+	Option struct {
+		Repanic         bool
+		WaitForDelivery bool
+		Timeout         time.Duration
+	}
+)
+
+// Middleware capture the panic of the handler, flush the monitor when WaitForDelivery and panic again when Repanic,
+// respond HTTP 500 otherwise
+func Middleware(mntr monitor.Monitor, option Option) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					if mntr != nil {
+						mntr.CapturePanic(c.Request().Context(), r)
+						if option.WaitForDelivery {
+							mntr.Flush()
+						}
+					}
+					if option.Repanic {
+						panic(r)
+					}
+					err = echo.NewHTTPError(http.StatusInternalServerError)
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// SetNewTransaction start a transaction per request using monitor.HTTPMiddleware, named by the echo route
+// such as `GET /users/:id`. Swagger requests are skipped.
+func SetNewTransaction(mntr monitor.Monitor) echo.MiddlewareFunc {
+	middleware := echo.WrapMiddleware(monitor.HTTPMiddleware(mntr, monitor.HTTPOption{
+		Operation: OperationEcho,
+		Skipper:   skipSwagger,
+	}))
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return middleware(func(c echo.Context) error {
+			monitor.SetRoute(c.Request().Context(), c.Path())
+
+			err := next(c)
+			if err != nil {
+				// - write the error response now, so the transaction finish with its status code
+				c.Error(err)
+			}
+			return err
+		})
+	}
+}
+
+// Recovery capture the panic of the handler to mntr, log it and respond HTTP 500.
+// Use it after SetNewTransaction so the panic is captured inside the transaction of the request.
+func Recovery(mntr monitor.Monitor, log logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					request := c.Request()
+					monitor.Recovered(request.Context(), mntr, log, request.Method+" "+c.Path(), r)
+					err = echo.NewHTTPError(http.StatusInternalServerError)
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// ContextWithTransaction store the transaction of the echo request into ctx, to pass it to other packages
+func ContextWithTransaction(c echo.Context, ctx context.Context) context.Context {
+	if tr, ok := requestctx.TransactionFrom(c.Request().Context()); ok {
+		return tr.CreateNewTransactionContext(ctx)
+	}
+	return ctx
+}
+
+func skipSwagger(r *http.Request) bool {
+	return strings.Contains(r.URL.Path, "swagger")
+}
@@ -0,0 +1,513 @@
+package otelgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/neazossa/common-util-go/logger/logger"
+	"github.com/neazossa/common-util-go/monitor/monitor"
+	"github.com/neazossa/common-util-go/shared/requestctx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+const (
+	GRPCServerLoggerFormat = monitor.GRPCServerLoggerFormat
+	GRPCClientLoggerFormat = monitor.GRPCClientLoggerFormat
+
+	TracerName = "github.com/neazossa/common-util-go/monitor/implementations/opentelemetry/otelgo"
+
+	AttributeOperation = "operation"
+	AttributeCapture   = "capture"
+	EventMessage       = "message"
+	EventBreadcrumb    = "breadcrumb"
+
+	// DefaultTracesSampleRate is used when Option.TracesSampleRate is not set
+	DefaultTracesSampleRate = 0.1
+)
+
+type (
+	Option struct {
+		ServiceName      string
+		ServiceVersion   string
+		Environment      string
+		Endpoint         string            // OTLP gRPC collector, default localhost:4317
+		Insecure         bool              // disable TLS to the collector
+		Headers          map[string]string // sent with every export, such as authentication
+		Exporter         sdktrace.SpanExporter
+		TracesSampleRate float64 // from 0.0 - 1.0, 0 use DefaultTracesSampleRate, negative disable tracing, captures are always sampled
+		FlushTimeout     time.Duration
+	}
+
+	User struct {
+		ID    string
+		Email string `log:"mask"`
+	}
+
+	// Scope add tags and user to the captured errors and messages, SetScope accept monitor.Scope as well which is preferred
+	Scope struct {
+		Tags []monitor.Tag
+		User User
+	}
+
+	otelMonitor struct {
+		logger     logger.Logger
+		sampled    logger.Logger // used to dump grpc request and response
+		option     Option
+		provider   *sdktrace.TracerProvider
+		tracer     trace.Tracer
+		propagator propagation.TextMapPropagator
+		scope      *monitor.Scope
+		ctx        context.Context
+	}
+
+	// captureSampler sample the spans of Capture and CaptureMessage whatever the rate, an error is never dropped
+	// with the trace it happened in
+	captureSampler struct {
+		sdktrace.Sampler
+	}
+
+	transaction struct {
+		tick   monitor.Tick
+		tracer trace.Tracer
+		span   trace.Span
+		ctx    context.Context
+		start  time.Time
+	}
+)
+
+func init() {
+	logger.RegisterContextExtractor(traceFields)
+}
+
+// NewOpenTelemetryMonitoring export the transactions as OTel spans, through Option.Exporter when set
+// (e.g. tracetest.NewInMemoryExporter in tests) or to the OTLP gRPC collector otherwise.
+// The tracer provider and the W3C trace context propagator are registered globally.
+func NewOpenTelemetryMonitoring(logger logger.Logger, option Option) (monitor.Monitor, error) {
+	exporter := option.Exporter
+	if exporter == nil {
+		exporterOptions := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(option.Headers)}
+		if option.Endpoint != "" {
+			exporterOptions = append(exporterOptions, otlptracegrpc.WithEndpoint(option.Endpoint))
+		}
+		if option.Insecure {
+			exporterOptions = append(exporterOptions, otlptracegrpc.WithInsecure())
+		}
+
+		var err error
+		if exporter, err = otlptracegrpc.New(context.Background(), exporterOptions...); err != nil {
+			logger.Errorf("failed to start opentelemetry exporter: %v", err)
+			return nil, err
+		}
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(newSampler(option.TracesSampleRate)),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", option.ServiceName),
+			attribute.String("service.version", option.ServiceVersion),
+			attribute.String("deployment.environment", option.Environment),
+		)),
+	)
+
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+
+	return &otelMonitor{
+		logger:     logger,
+		sampled:    monitor.NewSampledLogger(logger),
+		option:     option,
+		provider:   provider,
+		tracer:     provider.Tracer(TracerName),
+		propagator: propagator,
+	}, nil
+}
+
+// Capture record err as exception event of a standalone span, the span id is returned as event id.
+// The span is sampled even when its trace is not, nil is returned when it is not recorded such as after shutdown.
+func (o *otelMonitor) Capture(err error) *string {
+	if err == nil {
+		return nil
+	}
+
+	return o.captureEvent("exception", func(span trace.Span) {
+		span.RecordError(err, trace.WithStackTrace(true))
+		span.SetStatus(codes.Error, err.Error())
+	})
+}
+
+func (o *otelMonitor) CaptureMessage(msg string) *string {
+	return o.captureEvent(EventMessage, func(span trace.Span) {
+		span.AddEvent(EventMessage, trace.WithAttributes(attribute.String(EventMessage, msg)))
+	})
+}
+
+// captureEvent record on a new span, child of the transaction of the context given to WithContext
+func (o *otelMonitor) captureEvent(name string, record func(span trace.Span)) *string {
+	ctx := context.Background()
+	if o.ctx != nil {
+		ctx = o.ctx
+		if tr, ok := requestctx.TransactionFrom(ctx); ok {
+			ctx = tr.CreateNewTransactionContext(ctx)
+		}
+	}
+
+	attributes := append(o.scopeAttributes(), attribute.Bool(AttributeCapture, true))
+	_, span := o.tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+	if !span.IsRecording() {
+		return nil
+	}
+
+	if o.scope != nil {
+		for _, breadcrumb := range o.scope.Breadcrumbs {
+			addBreadcrumb(span, breadcrumb)
+		}
+	}
+	record(span)
+	span.End()
+
+	id := span.SpanContext().SpanID().String()
+	return &id
+}
+
+// scopeAttributes flatten the scope, extras become extra.<key> and contexts <context>.<key> attributes
+func (o *otelMonitor) scopeAttributes() []attribute.KeyValue {
+	if o.scope == nil {
+		return nil
+	}
+
+	attributes := tagAttributes(o.scope.Tags)
+	if o.scope.Level != "" {
+		attributes = append(attributes, attribute.String("level", o.scope.Level))
+	}
+	if o.scope.User.ID != "" {
+		attributes = append(attributes, attribute.String("enduser.id", o.scope.User.ID))
+	}
+	for key, value := range o.scope.Extras {
+		attributes = append(attributes, attribute.String("extra."+key, fmt.Sprint(value)))
+	}
+	for name, values := range o.scope.Contexts {
+		for key, value := range values {
+			attributes = append(attributes, attribute.String(name+"."+key, fmt.Sprint(value)))
+		}
+	}
+	if len(o.scope.Fingerprint) != 0 {
+		attributes = append(attributes, attribute.StringSlice("fingerprint", o.scope.Fingerprint))
+	}
+	if o.scope.TransactionName != "" {
+		attributes = append(attributes, attribute.String("transaction", o.scope.TransactionName))
+	}
+	return attributes
+}
+
+func (o *otelMonitor) Flush() bool {
+	ctx := context.Background()
+	if o.option.FlushTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.option.FlushTimeout)
+		defer cancel()
+	}
+	return o.provider.ForceFlush(ctx) == nil
+}
+
+// SetScope accept monitor.Scope or Scope, the breadcrumbs of the scope are added as events of the captured span
+func (o *otelMonitor) SetScope(scope interface{}) monitor.Monitor {
+	var sc monitor.Scope
+
+	switch v := scope.(type) {
+	case monitor.Scope:
+		sc = v
+	case Scope:
+		sc = monitor.Scope{Tags: v.Tags, User: monitor.User{ID: v.User.ID, Email: v.User.Email}}
+	default:
+		o.logger.Error("failed to set opentelemetry scope")
+		return o
+	}
+
+	return &otelMonitor{
+		logger:     o.logger,
+		sampled:    o.sampled,
+		option:     o.option,
+		provider:   o.provider,
+		tracer:     o.tracer,
+		propagator: o.propagator,
+		scope:      &sc,
+		ctx:        o.ctx,
+	}
+}
+
+// WithContext return monitor recording the captured errors and messages inside the trace stored in ctx
+func (o *otelMonitor) WithContext(ctx context.Context) monitor.Monitor {
+	return &otelMonitor{
+		logger:     o.logger,
+		sampled:    o.sampled,
+		option:     o.option,
+		provider:   o.provider,
+		tracer:     o.tracer,
+		propagator: o.propagator,
+		scope:      o.scope,
+		ctx:        ctx,
+	}
+}
+
+// AddBreadcrumb add the breadcrumb as event of the span stored in ctx, dropped when there is no recording span
+func (o *otelMonitor) AddBreadcrumb(ctx context.Context, breadcrumb monitor.Breadcrumb) {
+	if tr, ok := requestctx.TransactionFrom(ctx); ok {
+		ctx = tr.CreateNewTransactionContext(ctx)
+	}
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		addBreadcrumb(span, breadcrumb)
+	}
+}
+
+func (o *otelMonitor) Recover() *string {
+	return o.CapturePanic(context.Background(), recover())
+}
+
+// CapturePanic record the recovered value with its stack trace on the span stored in ctx, on a new span otherwise
+func (o *otelMonitor) CapturePanic(ctx context.Context, r interface{}) *string {
+	err := monitor.PanicError(r)
+	if err == nil {
+		return nil
+	}
+
+	if tr, ok := requestctx.TransactionFrom(ctx); ok {
+		ctx = tr.CreateNewTransactionContext(ctx)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return o.Capture(err)
+	}
+
+	span.RecordError(err, trace.WithStackTrace(true), trace.WithAttributes(attribute.Bool("exception.escaped", true)))
+	span.SetStatus(codes.Error, err.Error())
+
+	id := span.SpanContext().SpanID().String()
+	return &id
+}
+
+func (o *otelMonitor) StartTransaction(ctx context.Context, tick monitor.Tick) monitor.Transaction {
+	return startTransaction(o.tracer, ctx, tick)
+}
+
+// NewTransactionFromContext start a child of the transaction or the span stored in context, a new trace otherwise
+func (o *otelMonitor) NewTransactionFromContext(ctx context.Context, tick monitor.Tick) monitor.Transaction {
+	if tr, ok := requestctx.TransactionFrom(ctx); ok {
+		return tr.StartChildTransaction(tick)
+	}
+	return o.StartTransaction(ctx, tick)
+}
+
+// Inject write W3C traceparent and baggage of the transaction stored in ctx, with sentry-trace for sentry services
+func (o *otelMonitor) Inject(ctx context.Context, carrier monitor.Carrier) {
+	if tr, ok := requestctx.TransactionFrom(ctx); ok {
+		ctx = tr.CreateNewTransactionContext(ctx)
+	}
+
+	o.propagator.Inject(ctx, carrier)
+
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	carrier.Set(monitor.HeaderSentryTrace, monitor.TraceParent{
+		TraceID: sc.TraceID().String(),
+		SpanID:  sc.SpanID().String(),
+		Sampled: sc.IsSampled(),
+	}.SentryTraceHeader())
+}
+
+// Extract continue the W3C trace found in carrier, falling back to sentry-trace
+func (o *otelMonitor) Extract(ctx context.Context, carrier monitor.Carrier) context.Context {
+	ctx = o.propagator.Extract(ctx, carrier)
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	parent, ok := monitor.ParseSentryTrace(carrier.Get(monitor.HeaderSentryTrace))
+	if !ok {
+		return ctx
+	}
+
+	traceID, _ := trace.TraceIDFromHex(parent.TraceID)
+	spanID, _ := trace.SpanIDFromHex(parent.SpanID)
+	config := trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, Remote: true}
+	if parent.Sampled {
+		config.TraceFlags = trace.FlagsSampled
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(config))
+}
+
+func (o *otelMonitor) GRPCServerMonitor() grpc.UnaryServerInterceptor {
+	return monitor.GRPCServerInterceptor(o, o.grpcOption())
+}
+
+func (o *otelMonitor) GRPCClientMonitor() grpc.UnaryClientInterceptor {
+	return monitor.GRPCClientInterceptor(o, o.grpcOption())
+}
+
+// GRPCStreamServerMonitor create a span per stream, a panic of the handler is recorded and returned as codes.Internal
+func (o *otelMonitor) GRPCStreamServerMonitor() grpc.StreamServerInterceptor {
+	return monitor.GRPCStreamServerInterceptor(o, o.grpcOption())
+}
+
+// GRPCStreamClientMonitor create a span per stream, ended when the server end the stream
+func (o *otelMonitor) GRPCStreamClientMonitor() grpc.StreamClientInterceptor {
+	return monitor.GRPCStreamClientInterceptor(o, o.grpcOption())
+}
+
+// grpcOption record the error of a call as exception event of its span
+func (o *otelMonitor) grpcOption() monitor.GRPCOption {
+	return monitor.GRPCOption{
+		Logger:      o.logger,
+		Sampled:     o.sampled,
+		RequestID:   requestctx.RequestID,
+		RecordError: recordError,
+	}
+}
+
+// newSampler sample the new traces at rate, the traces continued from a caller follow the decision of the caller
+// - the spans of the captured errors and messages are always sampled
+func newSampler(rate float64) sdktrace.Sampler {
+	switch {
+	case rate == 0:
+		rate = DefaultTracesSampleRate
+	case rate < 0:
+		return captureSampler{sdktrace.ParentBased(sdktrace.NeverSample())}
+	}
+	return captureSampler{sdktrace.ParentBased(sdktrace.TraceIDRatioBased(rate))}
+}
+
+func (s captureSampler) ShouldSample(parameters sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	for _, attr := range parameters.Attributes {
+		if attr.Key == AttributeCapture && attr.Value.AsBool() {
+			return sdktrace.SamplingResult{
+				Decision:   sdktrace.RecordAndSample,
+				Tracestate: trace.SpanContextFromContext(parameters.ParentContext).TraceState(),
+			}
+		}
+	}
+	return s.Sampler.ShouldSample(parameters)
+}
+
+func startTransaction(tracer trace.Tracer, ctx context.Context, tick monitor.Tick) *transaction {
+	name := tick.TransactionName
+	if name == "" {
+		name = tick.Operation
+	}
+
+	attributes := append(tagAttributes(tick.Tags), attribute.String(AttributeOperation, tick.Operation))
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+
+	return &transaction{
+		tick:   tick,
+		tracer: tracer,
+		span:   span,
+		ctx:    ctx,
+		start:  time.Now(),
+	}
+}
+
+func (t *transaction) SetName(name string) {
+	t.tick.TransactionName = name
+	t.span.SetName(name)
+}
+
+func (t *transaction) Finish() {
+	t.span.End()
+}
+
+func (t *transaction) FinishWithTags(tags []monitor.Tag) {
+	t.span.SetAttributes(tagAttributes(tags)...)
+	t.span.End()
+}
+
+func (t *transaction) Info() monitor.TransactionInfo {
+	info := monitor.TransactionInfo{
+		Tick:  t.tick,
+		Ctx:   t.ctx,
+		Start: t.start,
+	}
+
+	// - the sdk span expose its end time and status, a non recording span does not
+	if span, ok := t.span.(sdktrace.ReadOnlySpan); ok {
+		info.Start = span.StartTime()
+		info.End = span.EndTime()
+		info.Status = span.Status().Code.String()
+		info.StatusCode = uint8(span.Status().Code)
+	}
+	return info
+}
+
+func (t *transaction) StartChildTransaction(tick monitor.Tick) monitor.Transaction {
+	return startTransaction(t.tracer, t.ctx, tick)
+}
+
+// CreateNewTransactionContext store the transaction and its span, so both otel instrumentation and
+// NewTransactionFromContext continue the trace
+func (t *transaction) CreateNewTransactionContext(ctx context.Context) context.Context {
+	return requestctx.WithTransaction(trace.ContextWithSpan(ctx, t.span), t)
+}
+
+func tagAttributes(tags []monitor.Tag) []attribute.KeyValue {
+	attributes := make([]attribute.KeyValue, 0, len(tags)+1)
+	for _, tag := range tags {
+		attributes = append(attributes, attribute.String(tag.Key, tag.Value))
+	}
+	return attributes
+}
+
+// addBreadcrumb add the breadcrumb as event named by its category, its data become attributes
+func addBreadcrumb(span trace.Span, breadcrumb monitor.Breadcrumb) {
+	name := breadcrumb.Category
+	if name == "" {
+		name = EventBreadcrumb
+	}
+
+	attributes := []attribute.KeyValue{
+		attribute.String(EventMessage, breadcrumb.Message),
+		attribute.String("type", breadcrumb.Type),
+		attribute.String("level", breadcrumb.Level),
+	}
+	for key, value := range breadcrumb.Data {
+		attributes = append(attributes, attribute.String(key, fmt.Sprint(value)))
+	}
+
+	options := []trace.EventOption{trace.WithAttributes(attributes...)}
+	if !breadcrumb.Timestamp.IsZero() {
+		options = append(options, trace.WithTimestamp(breadcrumb.Timestamp))
+	}
+	span.AddEvent(name, options...)
+}
+
+// recordError add err as exception event of the transaction span
+func recordError(tr monitor.Transaction, err error) {
+	if t, ok := tr.(*transaction); ok {
+		t.span.RecordError(err)
+		t.span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// traceFields put the W3C trace and span id of the span stored in context into every log entry
+func traceFields(ctx context.Context) map[string]interface{} {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return map[string]interface{}{
+			logger.FieldTraceID: sc.TraceID().String(),
+			logger.FieldSpanID:  sc.SpanID().String(),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package otelgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/neazossa/common-util-go/logger/logger/loggertest"
+	"github.com/neazossa/common-util-go/monitor/monitor"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestMonitor(t *testing.T, rate float64) (monitor.Monitor, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	mntr, err := NewOpenTelemetryMonitoring(loggertest.New(t), Option{
+		ServiceName:      "test",
+		Exporter:         exporter,
+		TracesSampleRate: rate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mntr, exporter
+}
+
+// spans return the exported spans by name, after flushing the batcher
+func spans(t *testing.T, mntr monitor.Monitor, exporter *tracetest.InMemoryExporter) map[string]tracetest.SpanStub {
+	t.Helper()
+
+	if !mntr.Flush() {
+		t.Fatal("flush failed")
+	}
+
+	byName := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		byName[span.Name] = span
+	}
+	return byName
+}
+
+func TestTransactionParenting(t *testing.T) {
+	mntr, exporter := newTestMonitor(t, 1)
+
+	root := mntr.StartTransaction(context.Background(), monitor.Tick{Operation: "http", TransactionName: "GET /orders"})
+	ctx := root.CreateNewTransactionContext(context.Background())
+
+	child := mntr.NewTransactionFromContext(ctx, monitor.Tick{Operation: "db", TransactionName: "GET ALL"})
+	grandChild := child.StartChildTransaction(monitor.Tick{Operation: "db", TransactionName: "SCAN"})
+	grandChild.Finish()
+	child.Finish()
+	root.Finish()
+
+	byName := spans(t, mntr, exporter)
+	if len(byName) != 3 {
+		t.Fatalf("exported %d spans, want 3", len(byName))
+	}
+	rootSpan, childSpan, grandChildSpan := byName["GET /orders"], byName["GET ALL"], byName["SCAN"]
+
+	if rootSpan.Parent.IsValid() {
+		t.Errorf("root has parent %s", rootSpan.Parent.SpanID())
+	}
+	if childSpan.Parent.SpanID() != rootSpan.SpanContext.SpanID() {
+		t.Errorf("child parent = %s, want %s", childSpan.Parent.SpanID(), rootSpan.SpanContext.SpanID())
+	}
+	if grandChildSpan.Parent.SpanID() != childSpan.SpanContext.SpanID() {
+		t.Errorf("grand child parent = %s, want %s", grandChildSpan.Parent.SpanID(), childSpan.SpanContext.SpanID())
+	}
+	for name, span := range byName {
+		if span.SpanContext.TraceID() != rootSpan.SpanContext.TraceID() {
+			t.Errorf("%s is in trace %s, want %s", name, span.SpanContext.TraceID(), rootSpan.SpanContext.TraceID())
+		}
+	}
+}
+
+func TestCaptureInTransaction(t *testing.T) {
+	mntr, exporter := newTestMonitor(t, 1)
+
+	root := mntr.StartTransaction(context.Background(), monitor.Tick{Operation: "http", TransactionName: "POST /orders"})
+	ctx := root.CreateNewTransactionContext(context.Background())
+
+	id := mntr.WithContext(ctx).SetScope(monitor.Scope{
+		Tags:   []monitor.Tag{{Key: "order", Value: "1"}},
+		Extras: map[string]interface{}{"amount": 10},
+	}).Capture(errors.New("payment declined"))
+	root.Finish()
+
+	byName := spans(t, mntr, exporter)
+	exception, ok := byName["exception"]
+	if !ok {
+		t.Fatal("captured error not exported")
+	}
+	if id == nil || *id != exception.SpanContext.SpanID().String() {
+		t.Errorf("event id = %v, want %s", id, exception.SpanContext.SpanID())
+	}
+	if rootSpan := byName["POST /orders"]; exception.Parent.SpanID() != rootSpan.SpanContext.SpanID() {
+		t.Errorf("exception parent = %s, want %s", exception.Parent.SpanID(), rootSpan.SpanContext.SpanID())
+	}
+	if exception.Status.Code != codes.Error || exception.Status.Description != "payment declined" {
+		t.Errorf("status = %+v", exception.Status)
+	}
+
+	attributes := make(map[string]string)
+	for _, attribute := range exception.Attributes {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+	if attributes["order"] != "1" || attributes["extra.amount"] != "10" {
+		t.Errorf("attributes = %v", attributes)
+	}
+
+	recorded := false
+	for _, event := range exception.Events {
+		recorded = recorded || event.Name == "exception"
+	}
+	if !recorded {
+		t.Errorf("exception event not recorded, events = %+v", exception.Events)
+	}
+}
+
+func TestTracesSampleRate(t *testing.T) {
+	if description := newSampler(0).Description(); !strings.Contains(description, fmt.Sprintf("TraceIDRatioBased{%g}", DefaultTracesSampleRate)) {
+		t.Errorf("sampler of unset rate = %s, want the default ratio", description)
+	}
+
+	mntr, exporter := newTestMonitor(t, -1)
+	mntr.StartTransaction(context.Background(), monitor.Tick{Operation: "http", TransactionName: "GET /orders"}).Finish()
+	if exported := spans(t, mntr, exporter); len(exported) != 0 {
+		t.Errorf("negative rate exported %d spans", len(exported))
+	}
+}
+
+func TestCaptureSampledWithoutTrace(t *testing.T) {
+	mntr, exporter := newTestMonitor(t, -1)
+
+	root := mntr.StartTransaction(context.Background(), monitor.Tick{Operation: "http", TransactionName: "POST /orders"})
+	ctx := root.CreateNewTransactionContext(context.Background())
+
+	id := mntr.WithContext(ctx).Capture(errors.New("payment declined"))
+	standalone := mntr.Capture(errors.New("connection refused"))
+	root.Finish()
+
+	if !mntr.Flush() {
+		t.Fatal("flush failed")
+	}
+
+	exported := exporter.GetSpans()
+	if len(exported) != 2 {
+		t.Fatalf("exported %d spans, want the 2 captures", len(exported))
+	}
+	for i, want := range []*string{id, standalone} {
+		if want == nil || *want != exported[i].SpanContext.SpanID().String() {
+			t.Errorf("event id = %v, want %s", want, exported[i].SpanContext.SpanID())
+		}
+	}
+	if exported[0].Parent.TraceID() != exported[0].SpanContext.TraceID() || !exported[0].Parent.IsValid() {
+		t.Errorf("capture in transaction is not in its trace, parent = %+v", exported[0].Parent)
+	}
+}
@@ -0,0 +1,434 @@
+package recorder
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/neazossa/common-util-go/monitor/monitor"
+	"github.com/neazossa/common-util-go/shared/requestctx"
+	"google.golang.org/grpc"
+)
+
+type (
+	// Recorder keep captured errors, messages and the span tree in memory, to assert on monitoring in tests
+	Recorder struct {
+		mutex    *sync.RWMutex
+		state    *state
+		scope    interface{}
+		sequence *int
+		ctx      context.Context
+	}
+
+	state struct {
+		errors      []Capture
+		messages    []Capture
+		spans       []*Span
+		breadcrumbs []Breadcrumb
+	}
+
+	// Capture is an error or message given to Capture / CaptureMessage with the scope set at that time,
+	// Span is the transaction of the context given to WithContext, Panic tell the error was recovered from a panic
+	Capture struct {
+		ID      string
+		Err     error
+		Message string
+		Scope   interface{}
+		Span    *Span
+		Panic   bool
+	}
+
+	// Breadcrumb is given to AddBreadcrumb, Span is the transaction of its context
+	Breadcrumb struct {
+		monitor.Breadcrumb
+		Span *Span
+	}
+
+	// Span is a recorded transaction, Tags hold the tags of the tick followed by the tags given when finishing
+	Span struct {
+		ID       string
+		Tick     monitor.Tick
+		Tags     []monitor.Tag
+		Parent   *Span
+		Children []*Span
+		Start    time.Time
+		End      time.Time
+		Finished bool
+
+		recorder *Recorder
+		ctx      context.Context
+	}
+
+	// Matcher select spans in queries, it is called with the recorder locked so it read the span fields directly
+	Matcher func(span *Span) bool
+)
+
+// HeaderSpan carry the id of the span of the caller, the recorder continue it as parent in Extract
+const HeaderSpan = "x-recorder-span"
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		mutex:    &sync.RWMutex{},
+		state:    &state{},
+		sequence: new(int),
+	}
+}
+
+func (r *Recorder) nextID() string {
+	*r.sequence++
+	return strconv.Itoa(*r.sequence)
+}
+
+func (r *Recorder) Capture(err error) *string {
+	if err == nil {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	capture := Capture{ID: r.nextID(), Err: err, Message: err.Error(), Scope: r.scope, Span: spanFrom(r.ctx)}
+	r.state.errors = append(r.state.errors, capture)
+	return &capture.ID
+}
+
+func (r *Recorder) CaptureMessage(msg string) *string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	capture := Capture{ID: r.nextID(), Message: msg, Scope: r.scope, Span: spanFrom(r.ctx)}
+	r.state.messages = append(r.state.messages, capture)
+	return &capture.ID
+}
+
+// SetScope return recorder sharing the records, captures made through it keep scope
+func (r *Recorder) SetScope(scope interface{}) monitor.Monitor {
+	return &Recorder{
+		mutex:    r.mutex,
+		state:    r.state,
+		scope:    scope,
+		sequence: r.sequence,
+		ctx:      r.ctx,
+	}
+}
+
+// WithContext return recorder sharing the records, captures made through it keep the span stored in ctx
+func (r *Recorder) WithContext(ctx context.Context) monitor.Monitor {
+	return &Recorder{
+		mutex:    r.mutex,
+		state:    r.state,
+		scope:    r.scope,
+		sequence: r.sequence,
+		ctx:      ctx,
+	}
+}
+
+func (r *Recorder) AddBreadcrumb(ctx context.Context, breadcrumb monitor.Breadcrumb) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.state.breadcrumbs = append(r.state.breadcrumbs, Breadcrumb{Breadcrumb: breadcrumb, Span: spanFrom(ctx)})
+}
+
+func spanFrom(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+
+	if tr, ok := requestctx.TransactionFrom(ctx); ok {
+		span, _ := tr.(*Span)
+		return span
+	}
+	return nil
+}
+
+func (r *Recorder) Flush() bool {
+	return true
+}
+
+func (r *Recorder) Recover() *string {
+	return r.CapturePanic(context.Background(), recover())
+}
+
+func (r *Recorder) CapturePanic(ctx context.Context, rec interface{}) *string {
+	err := monitor.PanicError(rec)
+	if err == nil {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	capture := Capture{ID: r.nextID(), Err: err, Message: err.Error(), Scope: r.scope, Span: spanFrom(ctx), Panic: true}
+	r.state.errors = append(r.state.errors, capture)
+	return &capture.ID
+}
+
+func (r *Recorder) StartTransaction(ctx context.Context, tick monitor.Tick) monitor.Transaction {
+	return r.startSpan(ctx, nil, tick)
+}
+
+// NewTransactionFromContext start a child of the span stored in context, a root span otherwise
+func (r *Recorder) NewTransactionFromContext(ctx context.Context, tick monitor.Tick) monitor.Transaction {
+	if parent, ok := requestctx.TransactionFrom(ctx); ok {
+		return parent.StartChildTransaction(tick)
+	}
+	return r.StartTransaction(ctx, tick)
+}
+
+func (r *Recorder) startSpan(ctx context.Context, parent *Span, tick monitor.Tick) *Span {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	span := &Span{
+		ID:       r.nextID(),
+		Tick:     tick,
+		Tags:     append([]monitor.Tag(nil), tick.Tags...),
+		Parent:   parent,
+		Start:    time.Now(),
+		recorder: r,
+		ctx:      ctx,
+	}
+
+	if parent != nil {
+		parent.Children = append(parent.Children, span)
+	}
+	r.state.spans = append(r.state.spans, span)
+	return span
+}
+
+// Inject write the id of the span stored in ctx
+func (r *Recorder) Inject(ctx context.Context, carrier monitor.Carrier) {
+	if tr, ok := requestctx.TransactionFrom(ctx); ok {
+		if span, ok := tr.(*Span); ok {
+			carrier.Set(HeaderSpan, span.ID)
+		}
+	}
+}
+
+// Extract store the span injected by the caller in ctx, so NewTransactionFromContext start its child
+func (r *Recorder) Extract(ctx context.Context, carrier monitor.Carrier) context.Context {
+	id := carrier.Get(HeaderSpan)
+	if id == "" {
+		return ctx
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, span := range r.state.spans {
+		if span.ID == id {
+			return requestctx.WithTransaction(ctx, span)
+		}
+	}
+	return ctx
+}
+
+func (r *Recorder) GRPCServerMonitor() grpc.UnaryServerInterceptor {
+	return monitor.GRPCServerInterceptor(r, monitor.GRPCOption{RequestID: requestctx.RequestID})
+}
+
+func (r *Recorder) GRPCClientMonitor() grpc.UnaryClientInterceptor {
+	return monitor.GRPCClientInterceptor(r, monitor.GRPCOption{RequestID: requestctx.RequestID})
+}
+
+func (r *Recorder) GRPCStreamServerMonitor() grpc.StreamServerInterceptor {
+	return monitor.GRPCStreamServerInterceptor(r, monitor.GRPCOption{RequestID: requestctx.RequestID})
+}
+
+func (r *Recorder) GRPCStreamClientMonitor() grpc.StreamClientInterceptor {
+	return monitor.GRPCStreamClientInterceptor(r, monitor.GRPCOption{RequestID: requestctx.RequestID})
+}
+
+// Errors return the captured errors in capture order
+func (r *Recorder) Errors() []Capture {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]Capture(nil), r.state.errors...)
+}
+
+// Messages return the captured messages in capture order
+func (r *Recorder) Messages() []Capture {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]Capture(nil), r.state.messages...)
+}
+
+// Breadcrumbs return the added breadcrumbs in order
+func (r *Recorder) Breadcrumbs() []Breadcrumb {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]Breadcrumb(nil), r.state.breadcrumbs...)
+}
+
+// Spans return every span matching all matchers in start order
+func (r *Recorder) Spans(matchers ...Matcher) []*Span {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var spans []*Span
+	for _, span := range r.state.spans {
+		if span.match(matchers) {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// Span return the first span matching all matchers, nil when there is none
+func (r *Recorder) Span(matchers ...Matcher) *Span {
+	if spans := r.Spans(matchers...); len(spans) > 0 {
+		return spans[0]
+	}
+	return nil
+}
+
+// Roots return the spans started without parent
+func (r *Recorder) Roots() []*Span {
+	return r.Spans(func(span *Span) bool {
+		return span.Parent == nil
+	})
+}
+
+// Reset forget every record, the recorders created by SetScope are reset too
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	*r.state = state{}
+}
+
+func (s *Span) StartChildTransaction(tick monitor.Tick) monitor.Transaction {
+	return s.recorder.startSpan(s.ctx, s, tick)
+}
+
+func (s *Span) CreateNewTransactionContext(ctx context.Context) context.Context {
+	return requestctx.WithTransaction(ctx, s)
+}
+
+func (s *Span) SetName(name string) {
+	s.recorder.mutex.Lock()
+	defer s.recorder.mutex.Unlock()
+
+	s.Tick.TransactionName = name
+}
+
+func (s *Span) Finish() {
+	s.FinishWithTags(nil)
+}
+
+func (s *Span) FinishWithTags(tags []monitor.Tag) {
+	s.recorder.mutex.Lock()
+	defer s.recorder.mutex.Unlock()
+
+	s.Tags = append(s.Tags, tags...)
+	s.End = time.Now()
+	s.Finished = true
+}
+
+func (s *Span) Info() monitor.TransactionInfo {
+	s.recorder.mutex.RLock()
+	defer s.recorder.mutex.RUnlock()
+
+	return monitor.TransactionInfo{
+		Tick:  s.Tick,
+		Ctx:   s.ctx,
+		Start: s.Start,
+		End:   s.End,
+	}
+}
+
+// Tag return the last value of key, finish tags override the tags of the tick
+func (s *Span) Tag(key string) (string, bool) {
+	s.recorder.mutex.RLock()
+	defer s.recorder.mutex.RUnlock()
+
+	return s.tag(key)
+}
+
+func (s *Span) tag(key string) (string, bool) {
+	for i := len(s.Tags) - 1; i >= 0; i-- {
+		if s.Tags[i].Key == key {
+			return s.Tags[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// Ancestor return the closest parent matching all matchers
+func (s *Span) Ancestor(matchers ...Matcher) *Span {
+	s.recorder.mutex.RLock()
+	defer s.recorder.mutex.RUnlock()
+
+	for parent := s.Parent; parent != nil; parent = parent.Parent {
+		if parent.match(matchers) {
+			return parent
+		}
+	}
+	return nil
+}
+
+// Within tell whether the span ran inside parent, directly or not
+func (s *Span) Within(parent *Span) bool {
+	for p := s.Parent; p != nil; p = p.Parent {
+		if p == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// Descendants return the spans started inside this span matching all matchers, depth first
+func (s *Span) Descendants(matchers ...Matcher) []*Span {
+	s.recorder.mutex.RLock()
+	defer s.recorder.mutex.RUnlock()
+
+	return s.descendants(matchers)
+}
+
+func (s *Span) descendants(matchers []Matcher) []*Span {
+	var spans []*Span
+	for _, child := range s.Children {
+		if child.match(matchers) {
+			spans = append(spans, child)
+		}
+		spans = append(spans, child.descendants(matchers)...)
+	}
+	return spans
+}
+
+func (s *Span) match(matchers []Matcher) bool {
+	for _, matcher := range matchers {
+		if !matcher(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Operation match the operation of the tick, such as db, redis, http or echo
+func Operation(operation string) Matcher {
+	return func(span *Span) bool {
+		return span.Tick.Operation == operation
+	}
+}
+
+// Name match the transaction name of the tick, such as the sql action or `GET /users`
+func Name(name string) Matcher {
+	return func(span *Span) bool {
+		return span.Tick.TransactionName == name
+	}
+}
+
+func HasTag(key, value string) Matcher {
+	return func(span *Span) bool {
+		v, ok := span.tag(key)
+		return ok && v == value
+	}
+}
+
+func Finished() Matcher {
+	return func(span *Span) bool {
+		return span.Finished
+	}
+}
@@ -0,0 +1,220 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/neazossa/common-util-go/monitor/monitor"
+)
+
+// run with -race, the spans are queried while the monitored code finish them
+
+func TestTransactions(t *testing.T) {
+	r := NewRecorder()
+
+	root := r.StartTransaction(context.Background(), monitor.Tick{Operation: "http", TransactionName: "GET /orders"})
+	ctx := root.CreateNewTransactionContext(context.Background())
+
+	child := r.NewTransactionFromContext(ctx, monitor.Tick{Operation: "db", TransactionName: "GET ALL"})
+	grandChild := child.StartChildTransaction(monitor.Tick{Operation: "redis", TransactionName: "GET"})
+	grandChild.Finish()
+	child.SetName("GET ORDERS")
+	child.Finish()
+
+	if roots := r.Roots(); len(roots) != 1 || roots[0] != root {
+		t.Fatalf("roots = %v, want the http span", roots)
+	}
+
+	db := r.Span(Operation("db"))
+	if db == nil || db.Tick.TransactionName != "GET ORDERS" || db.Parent != root {
+		t.Fatalf("db span = %+v, want renamed child of the http span", db)
+	}
+
+	redis := r.Span(Operation("redis"))
+	if !redis.Within(root.(*Span)) || redis.Ancestor(Operation("db")) != db {
+		t.Errorf("redis span is not inside the db span")
+	}
+	if descendants := root.(*Span).Descendants(Finished()); len(descendants) != 2 {
+		t.Errorf("finished descendants = %d, want 2", len(descendants))
+	}
+	if spans := r.Spans(Finished()); len(spans) != 2 {
+		t.Errorf("finished spans = %d, want 2, the root is still running", len(spans))
+	}
+
+	root.Finish()
+	if info := root.Info(); info.Tick.TransactionName != "GET /orders" || info.End.Before(info.Start) {
+		t.Errorf("root info = %+v", info)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	r := NewRecorder()
+
+	client := r.StartTransaction(context.Background(), monitor.Tick{Operation: "grpc.client"})
+	header := http.Header{}
+	r.Inject(client.CreateNewTransactionContext(context.Background()), monitor.HeaderCarrier(header))
+
+	ctx := r.Extract(context.Background(), monitor.HeaderCarrier(header))
+	server := r.NewTransactionFromContext(ctx, monitor.Tick{Operation: "grpc.server"})
+	if server.(*Span).Parent != client {
+		t.Errorf("server span does not continue the client span")
+	}
+
+	unknown := http.Header{}
+	unknown.Set(HeaderSpan, "404")
+	if ctx := r.Extract(context.Background(), monitor.HeaderCarrier(unknown)); spanFrom(ctx) != nil {
+		t.Errorf("unknown span extracted")
+	}
+}
+
+func TestTags(t *testing.T) {
+	r := NewRecorder()
+
+	tr := r.StartTransaction(context.Background(), monitor.Tick{
+		Operation: "db",
+		Tags:      []monitor.Tag{{"table", "orders"}, {"status", "running"}},
+	})
+	tr.FinishWithTags([]monitor.Tag{{"status", "ok"}})
+
+	span := tr.(*Span)
+	if status, _ := span.Tag("status"); status != "ok" {
+		t.Errorf("status = %s, want the finish tag to override the tick", status)
+	}
+	if _, ok := span.Tag("missing"); ok {
+		t.Errorf("missing tag found")
+	}
+	if r.Span(HasTag("table", "orders"), HasTag("status", "ok")) != span {
+		t.Errorf("span not matched by its tags")
+	}
+	if r.Span(HasTag("status", "running")) != nil {
+		t.Errorf("span matched by an overridden tag")
+	}
+}
+
+func TestCaptures(t *testing.T) {
+	r := NewRecorder()
+
+	tr := r.StartTransaction(context.Background(), monitor.Tick{Operation: "http"})
+	ctx := tr.CreateNewTransactionContext(context.Background())
+
+	scope := monitor.Scope{Tags: []monitor.Tag{{"order", "1"}}}
+	id := r.WithContext(ctx).SetScope(scope).Capture(errors.New("payment declined"))
+	r.CaptureMessage("retrying")
+	panicID := r.CapturePanic(ctx, "nil map")
+
+	if r.Capture(nil) != nil || r.CapturePanic(ctx, nil) != nil {
+		t.Errorf("nil error or panic captured")
+	}
+
+	errs := r.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("errors = %d, want 2", len(errs))
+	}
+	if errs[0].ID != *id || errs[0].Message != "payment declined" || errs[0].Span != tr || errs[0].Panic {
+		t.Errorf("capture = %+v", errs[0])
+	}
+	if sc, ok := errs[0].Scope.(monitor.Scope); !ok || sc.Tags[0].Value != "1" {
+		t.Errorf("capture scope = %+v", errs[0].Scope)
+	}
+	if errs[1].ID != *panicID || !errs[1].Panic || errs[1].Span != tr {
+		t.Errorf("panic capture = %+v", errs[1])
+	}
+
+	if messages := r.Messages(); len(messages) != 1 || messages[0].Message != "retrying" || messages[0].Span != nil {
+		t.Errorf("messages = %+v", messages)
+	}
+
+	r.Reset()
+	if len(r.Errors())+len(r.Messages())+len(r.Spans()) != 0 {
+		t.Errorf("records kept after reset")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	r := NewRecorder()
+
+	func() {
+		defer r.Recover()
+		panic("nil map")
+	}()
+
+	if errs := r.Errors(); len(errs) != 1 || !errs[0].Panic || errs[0].Span != nil {
+		t.Errorf("recovered panic = %+v", errs)
+	}
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	r := NewRecorder()
+
+	tr := r.StartTransaction(context.Background(), monitor.Tick{Operation: "kafka"})
+	r.AddBreadcrumb(tr.CreateNewTransactionContext(context.Background()), monitor.Breadcrumb{Category: "kafka", Message: "CONSUME orders"})
+	r.AddBreadcrumb(context.Background(), monitor.Breadcrumb{Category: "http"})
+
+	breadcrumbs := r.Breadcrumbs()
+	if len(breadcrumbs) != 2 {
+		t.Fatalf("breadcrumbs = %d, want 2", len(breadcrumbs))
+	}
+	if breadcrumbs[0].Message != "CONSUME orders" || breadcrumbs[0].Span != tr {
+		t.Errorf("breadcrumb = %+v, want the one added in the transaction", breadcrumbs[0])
+	}
+	if breadcrumbs[1].Span != nil {
+		t.Errorf("breadcrumb without transaction has span %+v", breadcrumbs[1].Span)
+	}
+}
+
+func TestConcurrentQueries(t *testing.T) {
+	r := NewRecorder()
+
+	root := r.StartTransaction(context.Background(), monitor.Tick{Operation: "http"})
+	span := root.(*Span)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			child := root.StartChildTransaction(monitor.Tick{Operation: "db"})
+			child.SetName("GET")
+			child.FinishWithTags([]monitor.Tag{{"status", "ok"}})
+		}()
+		go func() {
+			defer wg.Done()
+			span.Descendants(HasTag("status", "ok"))
+			span.Tag("status")
+			span.Info()
+			r.Spans(Name("GET"))
+		}()
+	}
+	wg.Wait()
+
+	if descendants := span.Descendants(Finished(), HasTag("status", "ok")); len(descendants) != 16 {
+		t.Errorf("finished descendants = %d, want 16", len(descendants))
+	}
+}
+
+func TestHTTPMiddlewareRoute(t *testing.T) {
+	r := NewRecorder()
+
+	handler := monitor.HTTPMiddleware(r, monitor.HTTPOption{})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/users/1" {
+			monitor.SetRoute(req.Context(), "/users/{id}")
+			return
+		}
+		http.NotFound(w, req)
+	}))
+
+	for _, target := range []string{"/users/1?token=secret", "/unknown/2?token=secret"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	if r.Span(Name("GET /users/{id}"), HasTag("http.target", "/users/1")) == nil {
+		t.Errorf("routed request not named by its route, spans = %+v", r.Spans())
+	}
+	if r.Span(Name("GET "+monitor.RouteUnmatched), HasTag("http.route", monitor.RouteUnmatched), HasTag("code", "404")) == nil {
+		t.Errorf("unmatched request not named %s, spans = %+v", monitor.RouteUnmatched, r.Spans())
+	}
+}
@@ -4,20 +4,21 @@ go 1.20
 
 require (
 	github.com/getsentry/sentry-go v0.21.0
-	github.com/google/uuid v1.3.0
-	github.com/labstack/echo/v4 v4.10.0
-	github.com/neazossa/common-util-go/logger/logger v1.0.0
-	github.com/neazossa/common-util-go/monitor/monitor v1.0.0
-	github.com/neazossa/common-util-go/shared/shared v1.0.0
-	github.com/pkg/errors v0.9.1
+	github.com/neazossa/common-util-go/logger/logger v1.1.0
+	github.com/neazossa/common-util-go/monitor/monitor v1.1.0
+	github.com/neazossa/common-util-go/shared/requestctx v1.0.0
 	google.golang.org/grpc v1.49.0
 )
 
 require (
 	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/labstack/echo/v4 v4.10.0 // indirect
 	github.com/labstack/gommon v0.4.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.17 // indirect
+	github.com/neazossa/common-util-go/shared/shared v1.1.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
 	golang.org/x/crypto v0.7.0 // indirect
@@ -2,32 +2,25 @@ package sentrygo
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"github.com/getsentry/sentry-go"
-	"github.com/labstack/echo/v4"
 	"net/http"
 	"strings"
 	"time"
 
-	sentryecho "github.com/getsentry/sentry-go/echo"
-	"github.com/google/uuid"
 	"github.com/neazossa/common-util-go/logger/logger"
 	"github.com/neazossa/common-util-go/monitor/monitor"
-	"github.com/neazossa/common-util-go/shared/shared"
-	"github.com/pkg/errors"
+	"github.com/neazossa/common-util-go/shared/requestctx"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/status"
 )
 
 const (
-	GRPCServerLoggerFormat = "entry from: %s ; request: %v"
-	GRPCClientLoggerFormat = "response from: %s ; request: %v ; response %v"
+	GRPCServerLoggerFormat = monitor.GRPCServerLoggerFormat
+	GRPCClientLoggerFormat = monitor.GRPCClientLoggerFormat
 )
 
 type (
 	Option struct {
-		Dsn              string
+		Dsn              string `config:",required,secret"`
 		Debug            bool
 		AttachStacktrace bool
 		IgnoreErrors     []string //regex
@@ -36,20 +29,22 @@ type (
 		Dist             string
 		Environment      string
 		MaxBreadcrumbs   int
-		HTTPClient       *http.Client
-		HTTPTransport    http.RoundTripper
+		HTTPClient       *http.Client      `config:"-"`
+		HTTPTransport    http.RoundTripper `config:"-"`
 		HTTPProxy        string
 		HTTPSProxy       string
-		FlushTimeout     time.Duration
+		FlushTimeout     time.Duration `config:",required"`
 		SampleRate       float64
 		TracesSampleRate float64
+		Transport        sentry.Transport `config:"-"` // replace the HTTP transport, e.g. to collect the events in tests
 	}
 
 	User struct {
 		ID    string
-		Email string
+		Email string `log:"mask"`
 	}
 
+	// Scope is the sentry only scope, SetScope accept monitor.Scope as well which is preferred
 	Scope struct {
 		Tags           []monitor.Tag
 		Level          string
@@ -58,11 +53,15 @@ type (
 		BreadcrumbHint sentry.BreadcrumbHint
 	}
 
+	// sentryMonitor is shared by every request, the hub of a request live in its context and is resolved by WithContext
 	sentryMonitor struct {
-		logger logger.Logger
-		option Option
-		hub    *sentry.Hub
-		scope  *Scope
+		logger  logger.Logger
+		sampled logger.Logger // used to dump grpc request and response
+		option  Option
+		hub     *sentry.Hub
+		scope   *monitor.Scope
+		hint    *sentry.BreadcrumbHint // given with the breadcrumbs of Scope
+		ctx     context.Context
 	}
 
 	transaction struct {
@@ -70,11 +69,23 @@ type (
 		span *sentry.Span
 	}
 
-	basicFunc func() *string
+	// remoteParent is the trace context extracted from the caller, continued by the next transaction
+	remoteParent struct {
+		trace   string
+		baggage string
+	}
+
+	remoteParentKey struct{}
+
+	basicFunc func(hub *sentry.Hub) *string
 )
 
+func init() {
+	logger.RegisterContextExtractor(traceFields)
+}
+
 func NewSentryMonitoring(logger logger.Logger, option Option) (monitor.Monitor, error) {
-	err := sentry.Init(sentry.ClientOptions{
+	client, err := sentry.NewClient(sentry.ClientOptions{
 		Dsn:              option.Dsn,
 		Debug:            option.Debug,
 		AttachStacktrace: option.AttachStacktrace,
@@ -90,6 +101,7 @@ func NewSentryMonitoring(logger logger.Logger, option Option) (monitor.Monitor,
 		IgnoreErrors:     option.IgnoreErrors,
 		SampleRate:       option.SampleRate,
 		TracesSampleRate: option.TracesSampleRate,
+		Transport:        option.Transport,
 	})
 
 	if err != nil {
@@ -97,10 +109,15 @@ func NewSentryMonitoring(logger logger.Logger, option Option) (monitor.Monitor,
 		return nil, err
 	}
 
+	// - bind the client like sentry.Init so the sentry package functions keep working,
+	//   the monitor itself use its own hub and never touch the global scope
+	sentry.CurrentHub().BindClient(client)
+
 	return &sentryMonitor{
-		logger: logger,
-		option: option,
-		hub:    sentry.CurrentHub(),
+		logger:  logger,
+		sampled: monitor.NewSampledLogger(logger),
+		option:  option,
+		hub:     sentry.NewHub(client, sentry.NewScope()),
 	}, nil
 }
 
@@ -109,71 +126,113 @@ func (s *sentryMonitor) Capture(err error) *string {
 		return nil
 	}
 
-	if s.scope != nil {
-		return s.implementScope(func() *string {
-			return getEventId(s.cloneHub().CaptureException(err))
-		})
-	}
-	return getEventId(s.cloneHub().CaptureException(err))
+	return s.implementScope(func(hub *sentry.Hub) *string {
+		return getEventId(hub.CaptureException(err))
+	})
 }
 
 func (s *sentryMonitor) CaptureMessage(msg string) *string {
-	if s.scope != nil {
-		return s.implementScope(func() *string {
-			return getEventId(s.cloneHub().CaptureMessage(msg))
-		})
-	}
-	return getEventId(s.cloneHub().CaptureMessage(msg))
+	return s.implementScope(func(hub *sentry.Hub) *string {
+		return getEventId(hub.CaptureMessage(msg))
+	})
 }
 
 func (s *sentryMonitor) Flush() bool {
 	return s.hub.Flush(s.option.FlushTimeout)
 }
 
+// SetScope accept monitor.Scope or Scope
 func (s *sentryMonitor) SetScope(scope interface{}) monitor.Monitor {
-	sc, ok := scope.(Scope)
-	if !ok {
+	var (
+		sc   monitor.Scope
+		hint *sentry.BreadcrumbHint
+	)
+
+	switch v := scope.(type) {
+	case monitor.Scope:
+		sc = v
+	case Scope:
+		sc, hint = v.monitorScope(), &v.BreadcrumbHint
+	default:
 		s.logger.Fatal("failed to set sentry scope")
 		return s
 	}
 
 	return &sentryMonitor{
-		logger: s.logger,
-		option: s.option,
-		hub:    s.hub,
-		scope:  &sc,
+		logger:  s.logger,
+		sampled: s.sampled,
+		option:  s.option,
+		hub:     s.hub,
+		scope:   &sc,
+		hint:    hint,
+		ctx:     s.ctx,
 	}
 }
 
-func (s *sentryMonitor) Recover() *string {
-	var (
-		err error
-	)
+// WithContext return monitor capturing on the hub of the request stored in ctx, with its request id and user
+func (s *sentryMonitor) WithContext(ctx context.Context) monitor.Monitor {
+	hub := s.hub
+	if ctxHub := sentry.GetHubFromContext(ctx); ctxHub != nil {
+		hub = ctxHub
+	}
 
-	r := recover()
+	return &sentryMonitor{
+		logger:  s.logger,
+		sampled: s.sampled,
+		option:  s.option,
+		hub:     hub,
+		scope:   s.scope,
+		hint:    s.hint,
+		ctx:     ctx,
+	}
+}
 
-	switch r.(type) {
-	case string:
-		err = errors.New(r.(string))
-	case error:
-		err = r.(error)
-	default:
-		err = errors.New(fmt.Sprintf("unknown error : %v", r))
+// AddBreadcrumb add the breadcrumb to the hub of the request stored in ctx, the events captured later in the request carry it.
+// Outside of a request the breadcrumb is dropped, it would be added to the events of every request otherwise.
+func (s *sentryMonitor) AddBreadcrumb(ctx context.Context, breadcrumb monitor.Breadcrumb) {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		hub.AddBreadcrumb(sentryBreadcrumb(breadcrumb), nil)
+	}
+}
+
+func (s *sentryMonitor) Recover() *string {
+	return s.CapturePanic(context.Background(), recover())
+}
+
+// CapturePanic capture the recovered value with its stack trace, tagged with the request id, user and trace stored in ctx
+func (s *sentryMonitor) CapturePanic(ctx context.Context, r interface{}) *string {
+	err := monitor.PanicError(r)
+	if err == nil {
+		return nil
 	}
-	return s.Capture(err)
+
+	return s.WithContext(ctx).(*sentryMonitor).implementScope(func(hub *sentry.Hub) *string {
+		hub.Scope().SetLevel(sentry.LevelFatal)
+		return getEventId(hub.CaptureException(err))
+	})
 }
 
 func (s *sentryMonitor) StartTransaction(ctx context.Context, tick monitor.Tick) monitor.Transaction {
 	var (
-		sp *sentry.Span
+		sp      *sentry.Span
+		options []sentry.SpanOption
 	)
 
-	if len(tick.TransactionName) == 0 {
-		sp = sentry.StartSpan(ctx, tick.Operation)
-	} else {
-		sp = sentry.StartSpan(ctx, tick.Operation, sentry.TransactionName(tick.TransactionName))
+	if len(tick.TransactionName) != 0 {
+		options = append(options, sentry.TransactionName(tick.TransactionName))
 	}
 
+	if parent, ok := ctx.Value(remoteParentKey{}).(remoteParent); ok {
+		options = append(options, sentry.ContinueFromHeaders(parent.trace, parent.baggage))
+	}
+
+	// - a new trace get its own hub, so the scope of concurrent requests never mix
+	if !sentry.HasHubOnContext(ctx) {
+		ctx = sentry.SetHubOnContext(ctx, s.hub.Clone())
+	}
+
+	sp = sentry.StartSpan(ctx, tick.Operation, options...)
+
 	for _, tag := range tick.Tags {
 		sp.SetTag(tag.Key, tag.Value)
 	}
@@ -185,153 +244,75 @@ func (s *sentryMonitor) StartTransaction(ctx context.Context, tick monitor.Tick)
 }
 
 func (s *sentryMonitor) NewTransactionFromContext(ctx context.Context, tick monitor.Tick) monitor.Transaction {
-	if val := ctx.Value("transaction"); val != nil {
-		if ctxTr, ok := val.(monitor.Transaction); ok {
-			return ctxTr.StartChildTransaction(monitor.Tick{
-				Operation: tick.Operation,
-				Tags:      tick.Tags,
-			})
-		}
+	if ctxTr, ok := requestctx.TransactionFrom(ctx); ok {
+		return ctxTr.StartChildTransaction(tick)
 	}
 	return s.StartTransaction(ctx, tick)
 }
 
-func (s *sentryMonitor) EchoMiddleware(echoOption monitor.EchoOption) echo.MiddlewareFunc {
-	return sentryecho.New(sentryecho.Options{
-		Repanic:         echoOption.Repanic,
-		WaitForDelivery: echoOption.WaitForDelivery,
-		Timeout:         echoOption.Timeout,
-	})
-}
-
-func (s *sentryMonitor) SetNewTransaction(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(ctx echo.Context) error {
-		if strings.Contains(ctx.Request().URL.Path, "swagger") {
-			return next(ctx)
-		}
-
-		if hub := sentryecho.GetHubFromContext(ctx); hub != nil {
-			s.hub = hub.Clone()
-
-			requestId := ""
-			if response := ctx.Get("Response"); response != nil {
-				if mapData, ok := response.(map[string]interface{}); ok && mapData != nil {
-					if data, ok := mapData["service_request_id"]; ok && data != nil {
-						if requestUuid, ok := data.(*uuid.UUID); ok {
-							requestId = requestUuid.String()
-						}
-					}
-				}
-			}
-
-			tags := []monitor.Tag{
-				{"requestID", requestId},
-			}
-
-			tr := s.StartTransaction(ctx.Request().Context(), monitor.Tick{
-				Operation:       "echo",
-				TransactionName: fmt.Sprintf("%s %s", ctx.Request().Method, ctx.Request().URL),
-				Tags:            tags,
-			})
-
-			ctx.Set("transaction", tr)
+// Inject write sentry-trace, baggage and W3C traceparent of the transaction stored in ctx
+func (s *sentryMonitor) Inject(ctx context.Context, carrier monitor.Carrier) {
+	tr, ok := transactionFrom(ctx)
+	if !ok || tr.span == nil {
+		return
+	}
 
-			defer tr.FinishWithTags(tags)
-		}
-		return next(ctx)
+	carrier.Set(monitor.HeaderSentryTrace, tr.span.ToSentryTrace())
+	if baggage := tr.span.ToBaggage(); baggage != "" {
+		carrier.Set(monitor.HeaderBaggage, baggage)
 	}
+	carrier.Set(monitor.HeaderTraceParent, monitor.TraceParent{
+		TraceID: tr.span.TraceID.String(),
+		SpanID:  tr.span.SpanID.String(),
+		Sampled: tr.span.Sampled.Bool(),
+	}.TraceParentHeader())
 }
 
-func (s *sentryMonitor) CreateNewContextWithTransaction(e echo.Context, ctx context.Context) context.Context {
-	tr := e.Get("transaction")
-
-	if tr != nil {
-		if trans, ok := tr.(monitor.Transaction); ok {
-			return trans.CreateNewTransactionContext(ctx)
-		}
+// Extract read traceparent or sentry-trace, the next transaction started from the returned context continue the trace
+func (s *sentryMonitor) Extract(ctx context.Context, carrier monitor.Carrier) context.Context {
+	parent, ok := monitor.TraceParentFrom(carrier)
+	if !ok {
+		return ctx
 	}
 
-	return ctx
+	return context.WithValue(ctx, remoteParentKey{}, remoteParent{
+		trace:   parent.SentryTraceHeader(),
+		baggage: carrier.Get(monitor.HeaderBaggage),
+	})
 }
 
 func (s *sentryMonitor) GRPCServerMonitor() grpc.UnaryServerInterceptor {
-	return func(
-		ctx context.Context,
-		req interface{},
-		info *grpc.UnaryServerInfo,
-		handler grpc.UnaryHandler,
-	) (interface{}, error) {
-		request, _ := json.Marshal(req)
-		m := make(map[string]interface{})
-		json.Unmarshal(request, &m)
-		s.logger.Info(fmt.Sprintf(GRPCServerLoggerFormat, info.FullMethod, m))
-
-		requestId, _ := shared.GetRequestId(req)
-
-		tr := s.StartTransaction(ctx, monitor.Tick{
-			Operation:       "grpc.server",
-			TransactionName: info.FullMethod,
-			Tags: []monitor.Tag{
-				{"requestId", requestId},
-			},
-		})
-
-		ctx2 := tr.CreateNewTransactionContext(ctx)
+	return monitor.GRPCServerInterceptor(s, s.grpcOption())
+}
 
-		res, err := handler(ctx2, req)
-		if err != nil {
-			s.Capture(err)
-			s.logger.Error(err)
-		}
+func (s *sentryMonitor) GRPCClientMonitor() grpc.UnaryClientInterceptor {
+	return monitor.GRPCClientInterceptor(s, s.grpcOption())
+}
 
-		defer func() {
-			errStats, _ := status.FromError(err)
-			tr.FinishWithTags([]monitor.Tag{
-				{"code", fmt.Sprintf("%d", errStats.Code())},
-				{"status", errStats.Code().String()},
-				{"message", errStats.Message()},
-			})
-		}()
-		return res, err
-	}
+// GRPCStreamServerMonitor create a transaction per stream, a panic of the handler is captured and returned as codes.Internal
+func (s *sentryMonitor) GRPCStreamServerMonitor() grpc.StreamServerInterceptor {
+	return monitor.GRPCStreamServerInterceptor(s, s.grpcOption())
 }
 
-func (s *sentryMonitor) GRPCClientMonitor() grpc.UnaryClientInterceptor {
-	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		requestId, _ := shared.GetRequestId(req)
-		tr := s.NewTransactionFromContext(ctx, monitor.Tick{
-			Operation:       "grpc.client",
-			TransactionName: method,
-			Tags: []monitor.Tag{
-				{"requestId", requestId},
-				{"action", method},
-			},
-		})
+// GRPCStreamClientMonitor create a transaction per stream, finished when the server end the stream
+func (s *sentryMonitor) GRPCStreamClientMonitor() grpc.StreamClientInterceptor {
+	return monitor.GRPCStreamClientInterceptor(s, s.grpcOption())
+}
 
-		err := invoker(ctx, method, req, reply, cc, opts...)
-		if err != nil {
-			s.logger.Error(err)
-			s.Capture(err)
-		}
-		defer func() {
-			errStats, _ := status.FromError(err)
-			tr.FinishWithTags([]monitor.Tag{
-				{"code", fmt.Sprintf("%d", errStats.Code())},
-				{"status", errStats.Code().String()},
-				{"message", errStats.Message()},
-			})
-			request, _ := json.Marshal(req)
-			response, _ := json.Marshal(reply)
-			mReq := make(map[string]interface{})
-			mRes := make(map[string]interface{})
-			json.Unmarshal(request, &mReq)
-			json.Unmarshal(response, &mRes)
-			s.logger.Info(fmt.Sprintf(GRPCClientLoggerFormat, method, mReq, mRes))
-		}()
-		return err
+// grpcOption capture the error of a call as sentry event in the transaction of the call
+func (s *sentryMonitor) grpcOption() monitor.GRPCOption {
+	return monitor.GRPCOption{
+		Logger:    s.logger,
+		Sampled:   s.sampled,
+		RequestID: requestctx.RequestID,
 	}
 }
 
+func (t *transaction) SetName(name string) {
+	t.tick.TransactionName = name
+	t.span.Name = name
+}
+
 func (t *transaction) Finish() {
 	t.span.Finish()
 }
@@ -356,6 +337,7 @@ func (t *transaction) Info() monitor.TransactionInfo {
 
 func (t *transaction) StartChildTransaction(tick monitor.Tick) monitor.Transaction {
 	sp := t.span.StartChild(tick.Operation)
+	sp.Description = tick.TransactionName
 
 	for _, tag := range tick.Tags {
 		sp.SetTag(tag.Key, tag.Value)
@@ -367,8 +349,31 @@ func (t *transaction) StartChildTransaction(tick monitor.Tick) monitor.Transacti
 	}
 }
 
+// CreateNewTransactionContext store the transaction and the hub of its request
 func (t *transaction) CreateNewTransactionContext(ctx context.Context) context.Context {
-	return context.WithValue(ctx, "transaction", t)
+	if hub := sentry.GetHubFromContext(t.span.Context()); hub != nil {
+		ctx = sentry.SetHubOnContext(ctx, hub)
+	}
+	return requestctx.WithTransaction(ctx, t)
+}
+
+// traceFields put trace and span id of the transaction stored in context into every log entry
+func traceFields(ctx context.Context) map[string]interface{} {
+	if tr, ok := transactionFrom(ctx); ok && tr.span != nil {
+		return map[string]interface{}{
+			logger.FieldTraceID: tr.span.TraceID.String(),
+			logger.FieldSpanID:  tr.span.SpanID.String(),
+		}
+	}
+	return nil
+}
+
+func transactionFrom(ctx context.Context) (*transaction, bool) {
+	if ctxTr, ok := requestctx.TransactionFrom(ctx); ok {
+		tr, ok := ctxTr.(*transaction)
+		return tr, ok
+	}
+	return nil, false
 }
 
 func getEventId(e *sentry.EventID) *string {
@@ -383,33 +388,120 @@ func (s *sentryMonitor) cloneHub() *sentry.Hub {
 	return s.hub.Clone()
 }
 
+// implementScope call f with a clone of the hub holding the request data of ctx and the scope set by SetScope
 func (s *sentryMonitor) implementScope(f basicFunc) *string {
 	var (
-		id *string
+		id  *string
+		hub = s.cloneHub()
 	)
 
-	s.cloneHub().WithScope(func(scope *sentry.Scope) {
-		switch strings.ToLower(s.scope.Level) {
-		case string(sentry.LevelDebug):
-			scope.SetLevel(sentry.LevelDebug)
-		case string(sentry.LevelError):
-			scope.SetLevel(sentry.LevelError)
-		case string(sentry.LevelFatal):
-			scope.SetLevel(sentry.LevelFatal)
-		case string(sentry.LevelWarning):
-			scope.SetLevel(sentry.LevelWarning)
-		default:
-			scope.SetLevel(sentry.LevelInfo)
+	hub.WithScope(func(scope *sentry.Scope) {
+		if s.ctx != nil {
+			requestScope(s.ctx, scope)
 		}
 
-		for _, tag := range s.scope.Tags {
-			scope.SetTag(tag.Key, tag.Value)
+		if s.scope != nil {
+			applyScope(hub, scope, s.scope, s.hint)
 		}
-		scope.SetUser(sentry.User{
-			Email: s.scope.User.Email,
-			ID:    s.scope.User.ID,
-		})
-		id = f()
+		id = f(hub)
 	})
 	return id
 }
+
+// applyScope copy sc into the sentry scope pushed on hub
+func applyScope(hub *sentry.Hub, scope *sentry.Scope, sc *monitor.Scope, hint *sentry.BreadcrumbHint) {
+	scope.SetLevel(sentryLevel(sc.Level))
+
+	for _, tag := range sc.Tags {
+		scope.SetTag(tag.Key, tag.Value)
+	}
+	if sc.User != (monitor.User{}) {
+		scope.SetUser(sentry.User{
+			ID:        sc.User.ID,
+			Email:     sc.User.Email,
+			Username:  sc.User.Username,
+			IPAddress: sc.User.IPAddress,
+		})
+	}
+	if len(sc.Extras) != 0 {
+		scope.SetExtras(sc.Extras)
+	}
+	for key, value := range sc.Contexts {
+		scope.SetContext(key, value)
+	}
+	if len(sc.Fingerprint) != 0 {
+		scope.SetFingerprint(sc.Fingerprint)
+	}
+	if name := sc.TransactionName; name != "" {
+		scope.AddEventProcessor(func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+			if event.Type != "transaction" {
+				event.Transaction = name
+			}
+			return event
+		})
+	}
+	// - through the hub so MaxBreadcrumbs is respected
+	for _, breadcrumb := range sc.Breadcrumbs {
+		hub.AddBreadcrumb(sentryBreadcrumb(breadcrumb), hint)
+	}
+}
+
+// monitorScope convert the sentry only scope, the breadcrumb is kept when it has a message or a category
+func (sc Scope) monitorScope() monitor.Scope {
+	scope := monitor.Scope{
+		Tags:  sc.Tags,
+		Level: sc.Level,
+		User:  monitor.User{ID: sc.User.ID, Email: sc.User.Email},
+	}
+
+	if b := sc.Breadcrumb; b.Message != "" || b.Category != "" {
+		scope.Breadcrumbs = []monitor.Breadcrumb{{
+			Type:      b.Type,
+			Category:  b.Category,
+			Message:   b.Message,
+			Data:      b.Data,
+			Level:     string(b.Level),
+			Timestamp: b.Timestamp,
+		}}
+	}
+	return scope
+}
+
+func sentryBreadcrumb(b monitor.Breadcrumb) *sentry.Breadcrumb {
+	breadcrumb := &sentry.Breadcrumb{
+		Type:      b.Type,
+		Category:  b.Category,
+		Message:   b.Message,
+		Data:      b.Data,
+		Timestamp: b.Timestamp,
+	}
+	if b.Level != "" {
+		breadcrumb.Level = sentryLevel(b.Level)
+	}
+	return breadcrumb
+}
+
+func sentryLevel(level string) sentry.Level {
+	switch strings.ToLower(level) {
+	case monitor.LevelDebug:
+		return sentry.LevelDebug
+	case monitor.LevelError:
+		return sentry.LevelError
+	case monitor.LevelFatal:
+		return sentry.LevelFatal
+	case monitor.LevelWarning:
+		return sentry.LevelWarning
+	default:
+		return sentry.LevelInfo
+	}
+}
+
+// requestScope tag the scope with the request id and the user stored in ctx
+func requestScope(ctx context.Context, scope *sentry.Scope) {
+	if requestId, ok := requestctx.RequestIDFrom(ctx); ok {
+		scope.SetTag("requestId", requestId)
+	}
+	if user, ok := requestctx.UserFrom(ctx); ok {
+		scope.SetUser(sentry.User{ID: user.ID, Email: user.Email})
+	}
+}
@@ -0,0 +1,251 @@
+package sentrygo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/neazossa/common-util-go/logger/logger/loggertest"
+	"github.com/neazossa/common-util-go/monitor/monitor"
+	"github.com/neazossa/common-util-go/shared/requestctx"
+)
+
+// run with -race, the requests below share one monitor like the services do
+
+const parallelRequests = 64
+
+type (
+	// eventTransport collect the events sent by the client instead of sending them to sentry
+	eventTransport struct {
+		mutex  sync.Mutex
+		events []*sentry.Event
+	}
+)
+
+func (t *eventTransport) Configure(options sentry.ClientOptions) {}
+
+func (t *eventTransport) SendEvent(event *sentry.Event) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *eventTransport) Flush(timeout time.Duration) bool {
+	return true
+}
+
+// errors return the captured events, transactions excluded, by message
+func (t *eventTransport) errors() map[string]*sentry.Event {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	events := make(map[string]*sentry.Event)
+	for _, event := range t.events {
+		if event.Type == "transaction" || len(event.Exception) == 0 {
+			continue
+		}
+		events[event.Exception[len(event.Exception)-1].Value] = event
+	}
+	return events
+}
+
+func newTestMonitor(t *testing.T) (monitor.Monitor, *eventTransport) {
+	t.Helper()
+
+	transport := &eventTransport{}
+	mntr, err := NewSentryMonitoring(loggertest.New(t), Option{
+		Transport:        transport,
+		TracesSampleRate: 1,
+		FlushTimeout:     time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mntr, transport
+}
+
+func TestCaptureIsolatedPerRequest(t *testing.T) {
+	mntr, transport := newTestMonitor(t)
+
+	var (
+		wg       sync.WaitGroup
+		started  sync.WaitGroup
+		mutex    sync.Mutex
+		traceIDs = make(map[string]string)
+	)
+
+	// - every request start its transaction before any capture, so a shared scope would mix them
+	started.Add(parallelRequests)
+	for i := 0; i < parallelRequests; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+
+			ctx := requestctx.WithRequestID(context.Background(), id)
+			ctx = requestctx.WithUser(ctx, requestctx.User{ID: "user-" + id})
+
+			tr := mntr.StartTransaction(ctx, monitor.Tick{Operation: "http.server", TransactionName: "GET /users/" + id})
+			ctx = tr.CreateNewTransactionContext(ctx)
+
+			child := mntr.NewTransactionFromContext(ctx, monitor.Tick{Operation: "db"})
+			started.Done()
+			started.Wait()
+
+			mntr.WithContext(ctx).SetScope(Scope{Tags: []monitor.Tag{{Key: "worker", Value: id}}}).Capture(errors.New("error " + id))
+			child.Finish()
+			tr.Finish()
+
+			span, _ := transactionFrom(ctx)
+			mutex.Lock()
+			traceIDs[id] = span.span.TraceID.String()
+			mutex.Unlock()
+		}(strconv.Itoa(i))
+	}
+	wg.Wait()
+	mntr.Flush()
+
+	events := transport.errors()
+	if len(events) != parallelRequests {
+		t.Fatalf("got %d events, want %d", len(events), parallelRequests)
+	}
+
+	for id, traceID := range traceIDs {
+		event, ok := events["error "+id]
+		if !ok {
+			t.Fatalf("missing event of request %s", id)
+		}
+
+		if got := event.Tags["requestId"]; got != id {
+			t.Errorf("request %s: requestId tag %q", id, got)
+		}
+		if got := event.Tags["worker"]; got != id {
+			t.Errorf("request %s: worker tag %q", id, got)
+		}
+		if got := event.User.ID; got != "user-"+id {
+			t.Errorf("request %s: user %q", id, got)
+		}
+		if got := fmt.Sprint(event.Contexts["trace"]["trace_id"]); got != traceID {
+			t.Errorf("request %s: trace %s, want %s", id, got, traceID)
+		}
+	}
+}
+
+func TestHTTPMiddlewareIsolatedPerRequest(t *testing.T) {
+	mntr, transport := newTestMonitor(t)
+
+	handler := requestctx.HTTPMiddleware(monitor.HTTPMiddleware(mntr, monitor.HTTPOption{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestId, _ := requestctx.RequestIDFrom(r.Context())
+			mntr.WithContext(r.Context()).Capture(errors.New("error " + requestId))
+			w.WriteHeader(http.StatusInternalServerError)
+		})))
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallelRequests; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+
+			request, _ := http.NewRequest(http.MethodGet, server.URL+"/users/"+id, nil)
+			request.Header.Set(requestctx.HeaderRequestID, id)
+			response, err := server.Client().Do(request)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			response.Body.Close()
+		}(strconv.Itoa(i))
+	}
+	wg.Wait()
+	mntr.Flush()
+
+	events := transport.errors()
+	if len(events) != parallelRequests {
+		t.Fatalf("got %d events, want %d", len(events), parallelRequests)
+	}
+
+	for message, event := range events {
+		if want := "error " + event.Tags["requestId"]; message != want {
+			t.Errorf("event %q tagged with request of %q", message, want)
+		}
+	}
+}
+
+func TestRecoverWithoutPanic(t *testing.T) {
+	mntr, transport := newTestMonitor(t)
+
+	func() {
+		defer mntr.Recover()
+	}()
+
+	mntr.Flush()
+	if events := transport.errors(); len(events) != 0 {
+		t.Fatalf("got %d events, want none", len(events))
+	}
+}
+
+func TestScopeAndBreadcrumbs(t *testing.T) {
+	mntr, transport := newTestMonitor(t)
+
+	ctx := requestctx.WithRequestID(context.Background(), "1")
+	tr := mntr.StartTransaction(ctx, monitor.Tick{Operation: "http.server", TransactionName: "GET /orders/1"})
+	ctx = tr.CreateNewTransactionContext(ctx)
+
+	// - outside of a request the breadcrumb must not reach the events of the requests
+	mntr.AddBreadcrumb(context.Background(), monitor.Breadcrumb{Category: "leak", Message: "leak"})
+
+	mntr.AddBreadcrumb(ctx, monitor.Breadcrumb{Type: monitor.BreadcrumbQuery, Category: "db", Message: "SELECT orders"})
+	mntr.WithContext(ctx).SetScope(monitor.Scope{
+		Level:           monitor.LevelWarning,
+		User:            monitor.User{ID: "user-1", Username: "john"},
+		Extras:          map[string]interface{}{"attempt": 2},
+		Contexts:        map[string]map[string]interface{}{"order": {"id": 1}},
+		Fingerprint:     []string{"order-failed"},
+		TransactionName: "pay order",
+		Breadcrumbs:     []monitor.Breadcrumb{{Category: "payment", Message: "charge"}},
+	}).Capture(errors.New("order failed"))
+	tr.Finish()
+	mntr.Flush()
+
+	event, ok := transport.errors()["order failed"]
+	if !ok {
+		t.Fatal("missing event")
+	}
+
+	if event.Level != sentry.LevelWarning {
+		t.Errorf("level %q", event.Level)
+	}
+	if event.User.ID != "user-1" || event.User.Username != "john" {
+		t.Errorf("user %+v", event.User)
+	}
+	if got := event.Extra["attempt"]; got != 2 {
+		t.Errorf("extra attempt %v", got)
+	}
+	if got := event.Contexts["order"]["id"]; got != 1 {
+		t.Errorf("context order.id %v", got)
+	}
+	if len(event.Fingerprint) != 1 || event.Fingerprint[0] != "order-failed" {
+		t.Errorf("fingerprint %v", event.Fingerprint)
+	}
+	if event.Transaction != "pay order" {
+		t.Errorf("transaction %q", event.Transaction)
+	}
+
+	var messages []string
+	for _, breadcrumb := range event.Breadcrumbs {
+		messages = append(messages, breadcrumb.Message)
+	}
+	if fmt.Sprint(messages) != "[SELECT orders charge]" {
+		t.Errorf("breadcrumbs %v", messages)
+	}
+}
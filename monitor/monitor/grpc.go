@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/neazossa/common-util-go/logger/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	GRPCServerLoggerFormat = "entry from: %s ; request: %v"
+	GRPCClientLoggerFormat = "response from: %s ; request: %v ; response %v"
+)
+
+type (
+	// GRPCOption is what the grpc interceptors of a monitor implementation do besides the transaction
+	// - Logger log the failed calls and the recovered panics, nothing is logged when nil
+	// - Sampled dump the request and response of the unary calls, see NewSampledLogger, nothing is dumped when nil
+	// - RequestID return the request id of the call tagged as requestId, such as requestctx.RequestID
+	// - RecordError record the error of the call into tr, captured in the context of tr when nil
+	GRPCOption struct {
+		Logger      logger.Logger
+		Sampled     logger.Logger
+		RequestID   func(ctx context.Context, req interface{}) string
+		RecordError func(tr Transaction, err error)
+	}
+)
+
+// GRPCServerInterceptor create a transaction per call continuing the trace of the client, used by the
+// GRPCServerMonitor of the monitor implementations
+func GRPCServerInterceptor(mntr Monitor, option GRPCOption) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if option.Sampled != nil {
+			option.Sampled.Infof(GRPCServerLoggerFormat, info.FullMethod, dump(req))
+		}
+
+		tags := option.tags(ctx, req)
+
+		// - continue the trace of the client
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			ctx = mntr.Extract(ctx, MetadataCarrier(md))
+		}
+
+		tr := mntr.NewTransactionFromContext(ctx, Tick{
+			Operation:       "grpc.server",
+			TransactionName: info.FullMethod,
+			Tags:            tags,
+		})
+		ctx = tr.CreateNewTransactionContext(ctx)
+
+		res, err := handler(ctx, req)
+		if err != nil {
+			option.failed(mntr, ctx, tr, err)
+		}
+
+		FinishGRPC(tr, err)
+		return res, err
+	}
+}
+
+// GRPCClientInterceptor create a transaction per call and send its trace context to the server
+func GRPCClientInterceptor(mntr Monitor, option GRPCOption) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		tr := mntr.NewTransactionFromContext(ctx, Tick{
+			Operation:       "grpc.client",
+			TransactionName: method,
+			Tags:            append(option.tags(ctx, req), Tag{"action", method}),
+		})
+		trCtx := tr.CreateNewTransactionContext(ctx)
+
+		// - send the trace context to the server
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		mntr.Inject(trCtx, MetadataCarrier(md))
+
+		err := invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+		if err != nil {
+			option.failed(mntr, trCtx, tr, err)
+		}
+
+		FinishGRPC(tr, err)
+		if option.Sampled != nil {
+			option.Sampled.Infof(GRPCClientLoggerFormat, method, dump(req), dump(reply))
+		}
+		return err
+	}
+}
+
+// GRPCStreamServerInterceptor create a transaction per stream, a panic of the handler is captured and returned as
+// PanicStatusError
+func GRPCStreamServerInterceptor(mntr Monitor, option GRPCOption) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx := ss.Context()
+		tags := option.tags(ctx, nil)
+
+		// - continue the trace of the client
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			ctx = mntr.Extract(ctx, MetadataCarrier(md))
+		}
+
+		tr := mntr.NewTransactionFromContext(ctx, Tick{
+			Operation:       "grpc.server",
+			TransactionName: info.FullMethod,
+			Tags: append(tags,
+				Tag{"stream.client", strconv.FormatBool(info.IsClientStream)},
+				Tag{"stream.server", strconv.FormatBool(info.IsServerStream)},
+			),
+		})
+
+		stream := WrapServerStream(ss, tr.CreateNewTransactionContext(ctx))
+
+		defer func() {
+			if r := recover(); r != nil {
+				Recovered(stream.Context(), mntr, option.Logger, info.FullMethod, r)
+				err = PanicStatusError()
+			} else if err != nil {
+				option.failed(mntr, stream.Context(), tr, err)
+			}
+			FinishGRPC(tr, err, stream.Stats().Tags()...)
+		}()
+		return handler(srv, stream)
+	}
+}
+
+// GRPCStreamClientInterceptor create a transaction per stream, finished when the server end the stream
+func GRPCStreamClientInterceptor(mntr Monitor, option GRPCOption) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		tr := mntr.NewTransactionFromContext(ctx, Tick{
+			Operation:       "grpc.client",
+			TransactionName: method,
+			Tags: append(option.tags(ctx, nil),
+				Tag{"action", method},
+				Tag{"stream.client", strconv.FormatBool(desc.ClientStreams)},
+				Tag{"stream.server", strconv.FormatBool(desc.ServerStreams)},
+			),
+		})
+		trCtx := tr.CreateNewTransactionContext(ctx)
+
+		// - send the trace context to the server
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		mntr.Inject(trCtx, MetadataCarrier(md))
+
+		finish := func(stats StreamStats, err error) {
+			if err != nil {
+				option.failed(mntr, trCtx, tr, err)
+			}
+			FinishGRPC(tr, err, stats.Tags()...)
+		}
+
+		cs, err := streamer(metadata.NewOutgoingContext(ctx, md), desc, cc, method, opts...)
+		if err != nil {
+			finish(StreamStats{}, err)
+			return cs, err
+		}
+		return WrapClientStream(ctx, cs, desc, finish), nil
+	}
+}
+
+// FinishGRPC finish tr with the grpc status of err, following the given tags
+func FinishGRPC(tr Transaction, err error, tags ...Tag) {
+	errStats, _ := status.FromError(err)
+	tr.FinishWithTags(append(tags,
+		Tag{"code", fmt.Sprintf("%d", errStats.Code())},
+		Tag{"status", errStats.Code().String()},
+		Tag{"message", errStats.Message()},
+	))
+}
+
+// NewSampledLogger create the logger given as GRPCOption.Sampled, create it once per monitor so the sampling
+// count every call
+func NewSampledLogger(l logger.Logger) logger.Logger {
+	return logger.NewSampler(l, logger.SamplerOption{})
+}
+
+func (o GRPCOption) tags(ctx context.Context, req interface{}) []Tag {
+	if o.RequestID == nil {
+		return nil
+	}
+	return []Tag{{"requestId", o.RequestID(ctx, req)}}
+}
+
+// failed record err of the call, ctx hold tr
+func (o GRPCOption) failed(mntr Monitor, ctx context.Context, tr Transaction, err error) {
+	if o.RecordError != nil {
+		o.RecordError(tr, err)
+	} else {
+		mntr.WithContext(ctx).Capture(err)
+	}
+
+	if o.Logger != nil {
+		o.Logger.WithContext(ctx).Error(err)
+	}
+}
+
+// dump convert a grpc message into map so it is logged as json
+func dump(v interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	data, _ := json.Marshal(v)
+	json.Unmarshal(data, &m)
+	return m
+}
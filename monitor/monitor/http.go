@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	OperationHTTPServer = "http.server"
+
+	// RouteUnmatched name the transactions of the requests without route pattern, such as 404, the raw path would
+	// make a transaction name per path
+	RouteUnmatched = "unmatched"
+)
+
+type (
+	// HTTPOption configure HTTPMiddleware
+	// - Operation of the transaction, default http.server
+	// - Route return the route pattern of the served request such as /users/{id}, called after the handler
+	//   so routers filling their route context while routing (e.g. chi RoutePattern) work. When it is nil or
+	//   return empty, the route given to SetRoute is used, then RouteUnmatched
+	// - Skipper return true for requests that must not be monitored, such as health check or swagger
+	HTTPOption struct {
+		Operation string
+		Route     func(r *http.Request) string
+		Skipper   func(r *http.Request) bool
+	}
+
+	routeKey struct{}
+
+	// statusWriter keep the status code written by the handler
+	statusWriter struct {
+		http.ResponseWriter
+		status int
+	}
+)
+
+// HTTPMiddleware start a transaction per request continuing the trace of the caller, and store it in the request context.
+// The transaction is named `METHOD route` and finished with the status code and the latency of the request.
+func HTTPMiddleware(mntr Monitor, option HTTPOption) func(http.Handler) http.Handler {
+	if option.Operation == "" {
+		option.Operation = OperationHTTPServer
+	}
+
+	return func(next http.Handler) http.Handler {
+		if mntr == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if option.Skipper != nil && option.Skipper(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			route := new(string)
+			ctx := context.WithValue(mntr.Extract(r.Context(), HeaderCarrier(r.Header)), routeKey{}, route)
+
+			tr := mntr.StartTransaction(ctx, Tick{
+				Operation:       option.Operation,
+				TransactionName: fmt.Sprintf("%s %s", r.Method, RouteUnmatched),
+				Tags: []Tag{
+					{"http.method", r.Method},
+					// - the query may carry tokens or personal data
+					{"http.target", r.URL.Path},
+				},
+			})
+
+			writer := &statusWriter{ResponseWriter: w}
+			r = r.WithContext(tr.CreateNewTransactionContext(ctx))
+
+			defer func() {
+				// - a panic not recovered by the handler is reported as 500, then panic again for the server
+				rec := recover()
+				if rec != nil {
+					writer.status = http.StatusInternalServerError
+				}
+
+				if option.Route != nil {
+					if pattern := option.Route(r); pattern != "" {
+						*route = pattern
+					}
+				}
+				if *route == "" {
+					*route = RouteUnmatched
+				}
+
+				status := writer.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				tr.SetName(fmt.Sprintf("%s %s", r.Method, *route))
+				tr.FinishWithTags([]Tag{
+					{"http.route", *route},
+					{"code", strconv.Itoa(status)},
+					{"status", http.StatusText(status)},
+					{"latency", time.Since(start).String()},
+				})
+
+				if rec != nil {
+					panic(rec)
+				}
+			}()
+			next.ServeHTTP(writer, r)
+		})
+	}
+}
+
+// SetRoute set the route pattern of the request served by HTTPMiddleware, for routers exposing it only inside
+// the handler such as echo Path() or gin FullPath()
+func SetRoute(ctx context.Context, route string) {
+	if holder, ok := ctx.Value(routeKey{}).(*string); ok {
+		*holder = route
+	}
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush keep streaming responses working through the wrapped writer
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap let http.ResponseController reach the wrapped writer
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,216 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/neazossa/common-util-go/logger/logger"
+)
+
+const (
+	DefaultLoggerDedupInterval = time.Minute
+
+	ExtraLogMessage = "log.message"
+	ExtraLogCaller  = "log.caller"
+	TagLogModule    = "module"
+
+	maxDedupKeys = 1024
+)
+
+type (
+	// LoggerOption configure DecorateLogger
+	// - EventLevel is the lowest level captured as event, default ERROR
+	// - BreadcrumbLevel is the lowest level added as breadcrumb, default INFO. Entries disabled by the level of
+	//   the logger are added too, so a debug trail is available when an error happen
+	// - DedupInterval capture the entries of the same log site and error once per interval, default 1 minute,
+	//   negative capture every entry
+	// - Masker mask the message and the fields before they are sent, the masker of the wrapped logger only
+	//   mask what is logged
+	// - Skip return true for entries that must not reach the monitor
+	LoggerOption struct {
+		EventLevel      logger.Level
+		BreadcrumbLevel logger.Level
+		DedupInterval   time.Duration
+		Masker          *logger.Masker
+		Skip            func(entry *logger.Entry) bool
+	}
+
+	loggerBridge struct {
+		monitor Monitor
+		option  LoggerOption
+		mutex   sync.Mutex
+		seen    map[string]time.Time
+	}
+)
+
+// DecorateLogger wrap l to forward its entries to mntr: entries from EventLevel are captured as events with their
+// fields as extras, the lower ones are added as breadcrumbs of the request stored in the context of the entry.
+// The monitor itself must log to a logger not decorated by it, its errors would be captured again otherwise.
+func DecorateLogger(l logger.Logger, mntr Monitor, option LoggerOption) logger.Logger {
+	if option.EventLevel == "" {
+		option.EventLevel = logger.Error
+	}
+	if option.BreadcrumbLevel == "" {
+		option.BreadcrumbLevel = logger.Info
+	}
+	if option.DedupInterval == 0 {
+		option.DedupInterval = DefaultLoggerDedupInterval
+	}
+
+	return logger.Decorate(l, &loggerBridge{
+		monitor: mntr,
+		option:  option,
+		seen:    make(map[string]time.Time),
+	})
+}
+
+// Intercept forward the entry to the monitor, the entry is never dropped
+func (b *loggerBridge) Intercept(entry *logger.Entry) bool {
+	if b.option.Skip != nil && b.option.Skip(entry) {
+		return true
+	}
+
+	switch {
+	case b.option.EventLevel.Enabled(entry.Level):
+		b.capture(entry)
+	case b.option.BreadcrumbLevel.Enabled(entry.Level):
+		b.monitor.AddBreadcrumb(entryContext(entry), Breadcrumb{
+			Type:      BreadcrumbDefault,
+			Category:  entryCategory(entry),
+			Message:   b.message(entry),
+			Data:      b.fields(entry),
+			Level:     monitorLevel(entry.Level),
+			Timestamp: time.Now(),
+		})
+	}
+	return true
+}
+
+func (b *loggerBridge) capture(entry *logger.Entry) {
+	err := entryError(entry)
+
+	key := string(entry.Level) + ":" + entry.Caller + ":" + entry.Template()
+	if err != nil {
+		key += ":" + err.Error()
+	}
+	if b.duplicate(key) {
+		return
+	}
+
+	extras := b.fields(entry)
+	if extras == nil {
+		extras = make(map[string]interface{}, 2)
+	}
+	extras[ExtraLogCaller] = entry.Caller
+
+	scope := Scope{
+		Level:  monitorLevel(entry.Level),
+		Extras: extras,
+	}
+	if entry.Module != "" {
+		scope.Tags = []Tag{{TagLogModule, entry.Module}}
+	}
+
+	mntr := b.monitor.WithContext(entryContext(entry))
+	if err != nil {
+		extras[ExtraLogMessage] = b.message(entry)
+		mntr.SetScope(scope).Capture(err)
+	} else {
+		mntr.SetScope(scope).CaptureMessage(b.message(entry))
+	}
+
+	// - the process exit or panic right after the entry is logged
+	if entry.Level == logger.Fatal || entry.Level == logger.Panic {
+		b.monitor.Flush()
+	}
+}
+
+// duplicate tell whether key was captured during the dedup interval, and remember it otherwise
+func (b *loggerBridge) duplicate(key string) bool {
+	if b.option.DedupInterval < 0 {
+		return false
+	}
+
+	now := time.Now()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if last, ok := b.seen[key]; ok && now.Sub(last) < b.option.DedupInterval {
+		return true
+	}
+
+	// - forget the expired keys so the map does not grow with every distinct error
+	if len(b.seen) >= maxDedupKeys {
+		for k, last := range b.seen {
+			if now.Sub(last) >= b.option.DedupInterval {
+				delete(b.seen, k)
+			}
+		}
+	}
+	b.seen[key] = now
+	return false
+}
+
+func (b *loggerBridge) message(entry *logger.Entry) string {
+	if b.option.Masker != nil {
+		return b.option.Masker.String(entry.Message())
+	}
+	return entry.Message()
+}
+
+// fields copy the fields of the entry, the scope keep them after the entry is logged
+func (b *loggerBridge) fields(entry *logger.Entry) map[string]interface{} {
+	if len(entry.Fields) == 0 {
+		return nil
+	}
+
+	if b.option.Masker != nil {
+		return b.option.Masker.InterceptFields(entry.Fields)
+	}
+	return logger.MergeFields(entry.Fields)
+}
+
+// entryError return the error given to WithError, then the first error argument such as log.Error(err) or log.Errorf("...: %v", err)
+func entryError(entry *logger.Entry) error {
+	if entry.Err != nil {
+		return entry.Err
+	}
+
+	for _, arg := range entry.Args {
+		if err, ok := arg.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+func entryContext(entry *logger.Entry) context.Context {
+	if entry.Context != nil {
+		return entry.Context
+	}
+	return context.Background()
+}
+
+func entryCategory(entry *logger.Entry) string {
+	if entry.Module != "" {
+		return "log." + entry.Module
+	}
+	return "log"
+}
+
+func monitorLevel(level logger.Level) string {
+	switch level {
+	case logger.Trace, logger.Debug:
+		return LevelDebug
+	case logger.Warn:
+		return LevelWarning
+	case logger.Error:
+		return LevelError
+	case logger.Fatal, logger.Panic:
+		return LevelFatal
+	default:
+		return LevelInfo
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/labstack/echo/v4"
 	"google.golang.org/grpc"
 )
 
@@ -13,27 +12,38 @@ type (
 		Capture(err error) *string
 		CaptureMessage(msg string) *string
 		SetScope(scope interface{}) Monitor
+		// - WithContext return monitor capturing within the request stored in ctx, safe for concurrent requests
+		WithContext(ctx context.Context) Monitor
+		// - AddBreadcrumb record what happened in the request stored in ctx, the events captured later in the request carry it
+		AddBreadcrumb(ctx context.Context, breadcrumb Breadcrumb)
 
 		Flush() bool
+		// - Recover must be deferred directly, CapturePanic capture the value recovered by the caller with the request context
 		Recover() *string
+		CapturePanic(ctx context.Context, r interface{}) *string
 
 		//Transaction Tracking
 		StartTransaction(ctx context.Context, span Tick) Transaction
 		NewTransactionFromContext(ctx context.Context, tick Tick) Transaction
 
-		//Echo Related
-		EchoMiddleware(echoOption EchoOption) echo.MiddlewareFunc
-		SetNewTransaction(next echo.HandlerFunc) echo.HandlerFunc
-		CreateNewContextWithTransaction(e echo.Context, ctx context.Context) context.Context
+		//Trace Propagation
+		// - Inject write the trace context of the transaction stored in ctx into carrier
+		// - Extract return ctx continuing the trace found in carrier, used by the next started transaction
+		Inject(ctx context.Context, carrier Carrier)
+		Extract(ctx context.Context, carrier Carrier) context.Context
 
 		//GRPC
 		GRPCServerMonitor() grpc.UnaryServerInterceptor
 		GRPCClientMonitor() grpc.UnaryClientInterceptor
+		GRPCStreamServerMonitor() grpc.StreamServerInterceptor
+		GRPCStreamClientMonitor() grpc.StreamClientInterceptor
 	}
 
 	Transaction interface {
 		StartChildTransaction(tick Tick) Transaction
 		CreateNewTransactionContext(ctx context.Context) context.Context
+		// - SetName rename the transaction, for names only known when it ends such as the matched route
+		SetName(name string)
 		Finish()
 		FinishWithTags(tags []Tag)
 		Info() TransactionInfo
@@ -58,10 +68,4 @@ type (
 		Status     string
 		StatusCode uint8
 	}
-
-	EchoOption struct {
-		Repanic         bool
-		WaitForDelivery bool
-		Timeout         time.Duration
-	}
 )
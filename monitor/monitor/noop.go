@@ -0,0 +1,123 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+type (
+	noopMonitor struct{}
+
+	noopTransaction struct {
+		tick  Tick
+		ctx   context.Context
+		start time.Time
+		end   time.Time
+	}
+)
+
+// NewNoopMonitor create monitor that track nothing, for services and tests running without monitoring
+func NewNoopMonitor() Monitor {
+	return noopMonitor{}
+}
+
+func (noopMonitor) Capture(err error) *string {
+	return nil
+}
+
+func (noopMonitor) CaptureMessage(msg string) *string {
+	return nil
+}
+
+func (n noopMonitor) SetScope(scope interface{}) Monitor {
+	return n
+}
+
+func (n noopMonitor) WithContext(ctx context.Context) Monitor {
+	return n
+}
+
+func (noopMonitor) AddBreadcrumb(ctx context.Context, breadcrumb Breadcrumb) {}
+
+func (noopMonitor) Flush() bool {
+	return true
+}
+
+// Recover swallow the panic like the other implementations, it must be deferred directly
+func (noopMonitor) Recover() *string {
+	recover()
+	return nil
+}
+
+func (noopMonitor) CapturePanic(ctx context.Context, r interface{}) *string {
+	return nil
+}
+
+func (noopMonitor) StartTransaction(ctx context.Context, tick Tick) Transaction {
+	return &noopTransaction{tick: tick, ctx: ctx, start: time.Now()}
+}
+
+func (n noopMonitor) NewTransactionFromContext(ctx context.Context, tick Tick) Transaction {
+	return n.StartTransaction(ctx, tick)
+}
+
+func (noopMonitor) Inject(ctx context.Context, carrier Carrier) {}
+
+func (noopMonitor) Extract(ctx context.Context, carrier Carrier) context.Context {
+	return ctx
+}
+
+func (noopMonitor) GRPCServerMonitor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+}
+
+func (noopMonitor) GRPCClientMonitor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (noopMonitor) GRPCStreamServerMonitor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, ss)
+	}
+}
+
+func (noopMonitor) GRPCStreamClientMonitor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func (t *noopTransaction) StartChildTransaction(tick Tick) Transaction {
+	return &noopTransaction{tick: tick, ctx: t.ctx, start: time.Now()}
+}
+
+func (t *noopTransaction) CreateNewTransactionContext(ctx context.Context) context.Context {
+	return ctx
+}
+
+func (t *noopTransaction) SetName(name string) {
+	t.tick.TransactionName = name
+}
+
+func (t *noopTransaction) Finish() {
+	t.end = time.Now()
+}
+
+func (t *noopTransaction) FinishWithTags(tags []Tag) {
+	t.end = time.Now()
+}
+
+func (t *noopTransaction) Info() TransactionInfo {
+	return TransactionInfo{
+		Tick:  t.tick,
+		Ctx:   t.ctx,
+		Start: t.start,
+		End:   t.end,
+	}
+}
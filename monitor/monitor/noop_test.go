@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestNoopMonitor(t *testing.T) {
+	mntr := NewNoopMonitor()
+	ctx := context.Background()
+
+	if mntr.Capture(errors.New("payment declined")) != nil || mntr.CaptureMessage("retrying") != nil {
+		t.Errorf("noop monitor returned an event id")
+	}
+	if mntr.WithContext(ctx).SetScope(Scope{Tags: []Tag{{"order", "1"}}}).CapturePanic(ctx, "nil map") != nil {
+		t.Errorf("noop monitor returned an event id for a panic")
+	}
+	mntr.AddBreadcrumb(ctx, Breadcrumb{Category: "http"})
+	if !mntr.Flush() {
+		t.Errorf("noop flush failed")
+	}
+
+	func() {
+		defer mntr.Recover()
+		panic("nil map")
+	}()
+}
+
+func TestNoopTransaction(t *testing.T) {
+	mntr := NewNoopMonitor()
+	ctx := context.WithValue(context.Background(), struct{}{}, "request")
+
+	tr := mntr.StartTransaction(ctx, Tick{Operation: "http", TransactionName: "GET /orders"})
+	if tr.CreateNewTransactionContext(ctx) != ctx {
+		t.Errorf("noop transaction changed the context")
+	}
+
+	child := mntr.NewTransactionFromContext(ctx, Tick{Operation: "db"}).StartChildTransaction(Tick{Operation: "redis"})
+	child.FinishWithTags([]Tag{{"status", "ok"}})
+	if info := child.Info(); info.Tick.Operation != "redis" || info.Ctx != ctx || info.End.Before(info.Start) {
+		t.Errorf("child info = %+v", info)
+	}
+
+	tr.SetName("GET /orders/:id")
+	tr.Finish()
+	if info := tr.Info(); info.Tick.TransactionName != "GET /orders/:id" || info.End.IsZero() {
+		t.Errorf("transaction info = %+v", info)
+	}
+}
+
+func TestNoopInterceptors(t *testing.T) {
+	mntr := NewNoopMonitor()
+	ctx := context.Background()
+
+	res, err := mntr.GRPCServerMonitor()(ctx, "request", &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req.(string) + " handled", nil
+		})
+	if err != nil || res != "request handled" {
+		t.Errorf("server interceptor = %v, %v", res, err)
+	}
+
+	invoked := false
+	err = mntr.GRPCClientMonitor()(ctx, "/orders.Orders/Get", "request", nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			invoked = true
+			return errors.New("unavailable")
+		})
+	if !invoked || err == nil || err.Error() != "unavailable" {
+		t.Errorf("client interceptor invoked = %t, err = %v", invoked, err)
+	}
+
+	handled := false
+	err = mntr.GRPCStreamServerMonitor()(nil, nil, &grpc.StreamServerInfo{FullMethod: "/orders.Orders/Watch"},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			handled = true
+			return nil
+		})
+	if !handled || err != nil {
+		t.Errorf("stream server interceptor handled = %t, err = %v", handled, err)
+	}
+}
@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	HeaderTraceParent = "traceparent"
+	HeaderSentryTrace = "sentry-trace"
+	HeaderBaggage     = "baggage"
+)
+
+type (
+	// Carrier hold the propagated trace context, such as http header, grpc metadata or kafka message headers
+	Carrier interface {
+		Get(key string) string
+		Set(key, value string)
+		Keys() []string
+	}
+
+	HeaderCarrier   http.Header
+	MetadataCarrier metadata.MD
+	MapCarrier      map[string]string
+
+	// TraceParent is the trace context shared by W3C traceparent and sentry-trace header
+	TraceParent struct {
+		TraceID string // 32 lowercase hex
+		SpanID  string // 16 lowercase hex
+		Sampled bool
+	}
+)
+
+func (c HeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+func (c HeaderCarrier) Set(key, value string) {
+	http.Header(c).Set(key, value)
+}
+
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (c MetadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+func (c MapCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c MapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// TraceParentFrom read the W3C traceparent header, then the sentry-trace header
+func TraceParentFrom(carrier Carrier) (TraceParent, bool) {
+	if parent, ok := ParseTraceParent(carrier.Get(HeaderTraceParent)); ok {
+		return parent, true
+	}
+	return ParseSentryTrace(carrier.Get(HeaderSentryTrace))
+}
+
+// ParseTraceParent parse `00-<trace id>-<span id>-<flags>`, flags must be 2 hex digits
+func ParseTraceParent(value string) (TraceParent, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return TraceParent{}, false
+	}
+
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return TraceParent{}, false
+	}
+
+	parent := TraceParent{
+		TraceID: strings.ToLower(parts[1]),
+		SpanID:  strings.ToLower(parts[2]),
+		Sampled: flags&0x01 == 0x01,
+	}
+	return parent, parent.valid()
+}
+
+// ParseSentryTrace parse `<trace id>-<span id>[-<sampled>]`
+func ParseSentryTrace(value string) (TraceParent, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 2 {
+		return TraceParent{}, false
+	}
+
+	parent := TraceParent{
+		TraceID: strings.ToLower(parts[0]),
+		SpanID:  strings.ToLower(parts[1]),
+		Sampled: len(parts) < 3 || parts[2] != "0",
+	}
+	return parent, parent.valid()
+}
+
+func (t TraceParent) TraceParentHeader() string {
+	flags := "00"
+	if t.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", t.TraceID, t.SpanID, flags)
+}
+
+func (t TraceParent) SentryTraceHeader() string {
+	sampled := "0"
+	if t.Sampled {
+		sampled = "1"
+	}
+	return fmt.Sprintf("%s-%s-%s", t.TraceID, t.SpanID, sampled)
+}
+
+func (t TraceParent) valid() bool {
+	return isHex(t.TraceID, 32) && isHex(t.SpanID, 16) &&
+		strings.Trim(t.TraceID, "0") != "" && strings.Trim(t.SpanID, "0") != ""
+}
+
+func isHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+
+	for _, c := range value {
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
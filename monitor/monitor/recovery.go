@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neazossa/common-util-go/logger/logger"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	FieldEventID   = "event_id"
+	FieldOperation = "operation"
+)
+
+// PanicError convert the value recovered from a panic into error carrying the stack trace, nil when there was no panic
+func PanicError(r interface{}) error {
+	switch v := r.(type) {
+	case nil:
+		return nil
+	case error:
+		return errors.WithStack(v)
+	case string:
+		return errors.New(v)
+	default:
+		return errors.Errorf("unknown error : %v", v)
+	}
+}
+
+// GRPCRecoveryInterceptor capture the panic of the handler to mntr, log it and return codes.Internal.
+// Chain it after GRPCServerMonitor so the panic is captured inside the transaction of the call.
+func GRPCRecoveryInterceptor(mntr Monitor, log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				Recovered(ctx, mntr, log, info.FullMethod, r)
+				err = PanicStatusError()
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// PanicStatusError is returned to the grpc client for a recovered panic, the panic value may hold internal state
+// so it is only captured to the monitor and logged
+func PanicStatusError() error {
+	return status.Error(codes.Internal, http.StatusText(http.StatusInternalServerError))
+}
+
+// GRPCStreamRecoveryInterceptor is GRPCRecoveryInterceptor for streaming calls
+func GRPCStreamRecoveryInterceptor(mntr Monitor, log logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				Recovered(ss.Context(), mntr, log, info.FullMethod, r)
+				err = PanicStatusError()
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// HTTPRecovery capture the panic of the handler to mntr, log it and respond HTTP 500.
+// Use it after HTTPMiddleware so the panic is captured inside the transaction of the request.
+func HTTPRecovery(mntr Monitor, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					// - http.ErrAbortHandler is the way to abort the response, not a failure
+					if rec == http.ErrAbortHandler {
+						panic(rec)
+					}
+					Recovered(r.Context(), mntr, log, r.Method+" "+r.URL.Path, rec)
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Recovered capture r to mntr and log it with its stack trace, used by the recovery middleware of the adapters
+func Recovered(ctx context.Context, mntr Monitor, log logger.Logger, operation string, r interface{}) {
+	fields := map[string]interface{}{
+		FieldOperation: operation,
+	}
+
+	if mntr != nil {
+		if id := mntr.CapturePanic(ctx, r); id != nil {
+			fields[FieldEventID] = *id
+		}
+	}
+
+	if log != nil {
+		log.WithContext(ctx).WithError(PanicError(r)).WithFields(fields).Error("panic recovered")
+	}
+}
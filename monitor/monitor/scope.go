@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"time"
+)
+
+const (
+	LevelDebug   = "debug"
+	LevelInfo    = "info"
+	LevelWarning = "warning"
+	LevelError   = "error"
+	LevelFatal   = "fatal"
+
+	BreadcrumbDefault = "default"
+	BreadcrumbHTTP    = "http"
+	BreadcrumbQuery   = "query"
+)
+
+type (
+	// Scope is given to SetScope, every event captured by the returned monitor carry it
+	// - Extras are free key values shown with the event, not searchable unlike Tags
+	// - Contexts group structured data under a name, e.g. "order": {"id": 1, "status": "paid"}
+	// - Fingerprint replace the default grouping of the events, {{ default }} keep it and add to it
+	// - TransactionName override the name of the transaction the event belong to
+	// - Breadcrumbs are added before the breadcrumbs recorded by AddBreadcrumb
+	Scope struct {
+		Tags            []Tag
+		Level           string
+		User            User
+		Extras          map[string]interface{}
+		Contexts        map[string]map[string]interface{}
+		Fingerprint     []string
+		TransactionName string
+		Breadcrumbs     []Breadcrumb
+	}
+
+	User struct {
+		ID        string
+		Email     string `log:"mask"`
+		Username  string
+		IPAddress string
+	}
+
+	// Breadcrumb is a trail of what happened in the request before an event, such as the queries and the calls made
+	Breadcrumb struct {
+		Type      string
+		Category  string
+		Message   string
+		Data      map[string]interface{}
+		Level     string
+		Timestamp time.Time
+	}
+)
+
+// TransactionBreadcrumb describe the finished transaction, used by the modules to leave a breadcrumb of every call
+func TransactionBreadcrumb(tr Transaction, breadcrumbType string) Breadcrumb {
+	info := tr.Info()
+
+	data := make(map[string]interface{}, len(info.Tags)+1)
+	for _, tag := range info.Tags {
+		data[tag.Key] = tag.Value
+	}
+	if !info.End.IsZero() {
+		data["duration"] = info.End.Sub(info.Start).String()
+	}
+
+	return Breadcrumb{
+		Type:      breadcrumbType,
+		Category:  info.Operation,
+		Message:   info.TransactionName,
+		Data:      data,
+		Level:     LevelInfo,
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	// StreamStats count the messages and their protobuf size of a grpc stream
+	StreamStats struct {
+		Sent          int
+		Received      int
+		SentBytes     int
+		ReceivedBytes int
+	}
+
+	// ServerStream serve the context holding the transaction to the stream handler and count its messages
+	ServerStream struct {
+		grpc.ServerStream
+		ctx   context.Context
+		mutex sync.Mutex
+		stats StreamStats
+	}
+
+	// ClientStream count the messages of a client stream and call finish once when the stream is over
+	ClientStream struct {
+		grpc.ClientStream
+		desc   *grpc.StreamDesc
+		finish func(stats StreamStats, err error)
+		once   sync.Once
+		mutex  sync.Mutex
+		stats  StreamStats
+	}
+)
+
+func WrapServerStream(ss grpc.ServerStream, ctx context.Context) *ServerStream {
+	return &ServerStream{ServerStream: ss, ctx: ctx}
+}
+
+func (s *ServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *ServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.mutex.Lock()
+		s.stats.Sent++
+		s.stats.SentBytes += messageSize(m)
+		s.mutex.Unlock()
+	}
+	return err
+}
+
+func (s *ServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.mutex.Lock()
+		s.stats.Received++
+		s.stats.ReceivedBytes += messageSize(m)
+		s.mutex.Unlock()
+	}
+	return err
+}
+
+func (s *ServerStream) Stats() StreamStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stats
+}
+
+// WrapClientStream call finish when the server ended the stream, io.EOF is reported as nil error, or when ctx given
+// to the interceptor is done since the caller usually stop reading a cancelled stream
+func WrapClientStream(ctx context.Context, cs grpc.ClientStream, desc *grpc.StreamDesc, finish func(stats StreamStats, err error)) *ClientStream {
+	c := &ClientStream{ClientStream: cs, desc: desc, finish: finish}
+	go c.watch(ctx)
+	return c
+}
+
+func (c *ClientStream) SendMsg(m interface{}) error {
+	err := c.ClientStream.SendMsg(m)
+	if err == nil {
+		c.mutex.Lock()
+		c.stats.Sent++
+		c.stats.SentBytes += messageSize(m)
+		c.mutex.Unlock()
+	} else if err != io.EOF {
+		// - io.EOF means the server ended the stream, the status is returned by RecvMsg
+		c.done(err)
+	}
+	return err
+}
+
+func (c *ClientStream) RecvMsg(m interface{}) error {
+	err := c.ClientStream.RecvMsg(m)
+	if err != nil {
+		if err == io.EOF {
+			c.done(nil)
+		} else {
+			c.done(err)
+		}
+		return err
+	}
+
+	c.mutex.Lock()
+	c.stats.Received++
+	c.stats.ReceivedBytes += messageSize(m)
+	c.mutex.Unlock()
+
+	// - the server send a single response when it does not stream
+	if !c.desc.ServerStreams {
+		c.done(nil)
+	}
+	return err
+}
+
+func (c *ClientStream) Stats() StreamStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.stats
+}
+
+// watch end with the stream, grpc cancel its context once the stream is over. The end of a stream that was not
+// cancelled by the caller is reported by SendMsg or RecvMsg instead
+func (c *ClientStream) watch(ctx context.Context) {
+	<-c.ClientStream.Context().Done()
+	if err := ctx.Err(); err != nil {
+		c.done(status.FromContextError(err).Err())
+	}
+}
+
+func (c *ClientStream) done(err error) {
+	c.once.Do(func() {
+		c.finish(c.Stats(), err)
+	})
+}
+
+// Tags return the counters as transaction tags
+func (s StreamStats) Tags() []Tag {
+	return []Tag{
+		{"messages.sent", strconv.Itoa(s.Sent)},
+		{"messages.received", strconv.Itoa(s.Received)},
+		{"bytes.sent", strconv.Itoa(s.SentBytes)},
+		{"bytes.received", strconv.Itoa(s.ReceivedBytes)},
+	}
+}
+
+// messageSize return the protobuf size of m, 0 when m is not a protobuf message
+func messageSize(m interface{}) int {
+	if message, ok := m.(proto.Message); ok {
+		return proto.Size(message)
+	}
+	return 0
+}
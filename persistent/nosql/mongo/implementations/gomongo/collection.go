@@ -31,8 +31,8 @@ func NewCollection(collection *mgo.Collection) mongo.Collection {
 func (c *collectionImplementation) Monitor(ctx context.Context, mntr monitor.Monitor, log logger.Logger, requestID string, captureError bool) mongo.Collection {
 	return &collectionImplementation{
 		collection:     c.collection,
-		isMonitor:      true,
-		isCaptureError: captureError,
+		isMonitor:      mntr != nil,
+		isCaptureError: captureError && mntr != nil,
 		monitor:        mntr,
 		logger:         log,
 		requestID:      requestID,
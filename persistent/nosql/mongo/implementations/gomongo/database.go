@@ -36,8 +36,8 @@ func NewDatabase(database *mgo.Database, logger logger.Logger) mongo.Database {
 func (d *databaseImplementation) Monitor(ctx context.Context, mntr monitor.Monitor, requestID string, captureError bool) mongo.Database {
 	return &databaseImplementation{
 		database:       d.database,
-		isMonitor:      true,
-		isCaptureError: captureError,
+		isMonitor:      mntr != nil,
+		isCaptureError: captureError && mntr != nil,
 		monitor:        mntr,
 		logger:         d.logger,
 		requestID:      requestID,
@@ -123,7 +123,7 @@ func (d *databaseImplementation) startMonitor(action string, database string) mo
 
 func (d *databaseImplementation) captureError(err error) error {
 	if d.isCaptureError {
-		d.monitor.Capture(err)
+		d.monitor.WithContext(d.ctx).Capture(err)
 	}
 	return err
 }
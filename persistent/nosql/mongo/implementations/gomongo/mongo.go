@@ -38,10 +38,10 @@ type (
 	decoder struct {
 	}
 	Connection struct {
-		Host     string
+		Host     string `config:",required"`
 		Username string
-		Password string
-		Database string
+		Password string `config:",secret"`
+		Database string `config:",required"`
 	}
 )
 
@@ -132,8 +132,8 @@ func (i *implementation) Monitor(ctx context.Context, mntr monitor.Monitor, requ
 		databaseName:   i.databaseName,
 		collectionName: i.collectionName,
 		collection:     i.collection,
-		isMonitor:      true,
-		isCaptureError: captureError,
+		isMonitor:      mntr != nil,
+		isCaptureError: captureError && mntr != nil,
 		monitor:        mntr,
 		logger:         i.logger,
 		requestID:      requestID,
@@ -425,7 +425,7 @@ func (i *implementation) startMonitor(action string, database, table string) mon
 
 func (i *implementation) captureError(err error) error {
 	if i.isCaptureError {
-		i.monitor.Capture(err)
+		i.monitor.WithContext(i.ctx).Capture(err)
 	}
 	return err
 }
@@ -1,9 +1,49 @@
 package postgres
 
 import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/neazossa/common-util-go/shared/shared"
+	"google.golang.org/grpc/codes"
 	"gorm.io/gorm"
 )
 
+// SQLSTATE codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	CodeNotNullViolation    = "23502"
+	CodeForeignKeyViolation = "23503"
+	CodeUniqueViolation     = "23505"
+	CodeCheckViolation      = "23514"
+	// CodeSerializationFailure is a transaction that could not be serialized with a concurrent one
+	CodeSerializationFailure = "40001"
+)
+
+// messages of GRPCError, the error itself hold the constraint and may hold the rejected values
+const (
+	messageUniqueViolation      = "record already exists"
+	messageForeignKeyViolation  = "referenced record does not exist or is still referenced"
+	messageSerializationFailure = "concurrent update, retry the request"
+	messageInvalidValue         = "invalid value"
+	messageInternal             = "internal database error"
+)
+
+type (
+	// Violation describe the constraint rejecting a write
+	// - Columns are the columns of the constraint, read from the detail for the unique and foreign key violations
+	// - Detail may hold the rejected values, it must not be returned to the client
+	Violation struct {
+		Code       string
+		Constraint string
+		Table      string
+		Columns    []string
+		Detail     string
+	}
+)
+
 var (
 	// ErrRecordNotFound record not found error
 	ErrRecordNotFound = gorm.ErrRecordNotFound
@@ -37,4 +77,100 @@ var (
 	ErrInvalidValue = gorm.ErrInvalidValue
 	// ErrInvalidValueOfLength invalid values do not match length
 	ErrInvalidValueOfLength = gorm.ErrInvalidValueOfLength
+	// ErrNestedTransactionOption option given to a nested Transaction, the options of the outer transaction apply
+	ErrNestedTransactionOption = errors.New("nested transaction does not accept option")
+
+	// detailKey read the columns from the detail of a violation :
+	// - Key (email)=(john@doe.com) already exists.
+	// - Key (user_id, role_id)=(1, 2) is not present in table "users".
+	detailKey = regexp.MustCompile(`^Key \((.+?)\)=`)
 )
+
+// IsUniqueViolation tell whether err is a duplicate of a unique index or primary key, such as an email already taken
+func IsUniqueViolation(err error) (Violation, bool) {
+	return violation(err, CodeUniqueViolation)
+}
+
+// IsForeignKeyViolation tell whether err reference a missing row, or delete a row still referenced
+func IsForeignKeyViolation(err error) (Violation, bool) {
+	return violation(err, CodeForeignKeyViolation)
+}
+
+// IsNotNullViolation tell whether err is a null written into a NOT NULL column, the column is the only one of Columns
+func IsNotNullViolation(err error) (Violation, bool) {
+	return violation(err, CodeNotNullViolation)
+}
+
+// IsCheckViolation tell whether err is a value rejected by a CHECK constraint
+func IsCheckViolation(err error) (Violation, bool) {
+	return violation(err, CodeCheckViolation)
+}
+
+// IsSerializationFailure tell whether the transaction failed because of a concurrent one, and can be retried
+func IsSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == CodeSerializationFailure
+}
+
+// HTTPStatus map err to the status returned to the client, the http status of GRPCError :
+// - ErrRecordNotFound give 404
+// - unique and foreign key violations, serialization failures give 409
+// - not null and check violations give 400
+// - other errors give 500
+func HTTPStatus(err error) int {
+	return shared.GrpcToHttpStatus(GRPCError(err))
+}
+
+// GRPCError convert err to a grpc status error with a generic message, the constraint must not reach the client :
+// - ErrRecordNotFound give NotFound
+// - unique violations give AlreadyExists
+// - foreign key violations give FailedPrecondition
+// - serialization failures give Aborted, the client may retry
+// - not null and check violations give InvalidArgument
+// - other errors give Internal
+func GRPCError(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrRecordNotFound):
+		return shared.HttpToGrpcError(http.StatusNotFound, ErrRecordNotFound)
+	case !errors.As(err, &pgErr):
+		return shared.HttpToGrpcError(http.StatusInternalServerError, errors.New(messageInternal))
+	}
+
+	switch pgErr.Code {
+	case CodeUniqueViolation:
+		return shared.HttpToGrpcError(http.StatusConflict, errors.New(messageUniqueViolation))
+	case CodeForeignKeyViolation:
+		return shared.GrpcError(codes.FailedPrecondition, errors.New(messageForeignKeyViolation))
+	case CodeSerializationFailure:
+		return shared.GrpcError(codes.Aborted, errors.New(messageSerializationFailure))
+	case CodeNotNullViolation, CodeCheckViolation:
+		return shared.HttpToGrpcError(http.StatusBadRequest, errors.New(messageInvalidValue))
+	default:
+		return shared.HttpToGrpcError(http.StatusInternalServerError, errors.New(messageInternal))
+	}
+}
+
+func violation(err error, code string) (Violation, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != code {
+		return Violation{}, false
+	}
+
+	v := Violation{
+		Code:       pgErr.Code,
+		Constraint: pgErr.ConstraintName,
+		Table:      pgErr.TableName,
+		Detail:     pgErr.Detail,
+	}
+	if pgErr.ColumnName != "" {
+		v.Columns = []string{pgErr.ColumnName}
+	} else if match := detailKey.FindStringSubmatch(pgErr.Detail); match != nil {
+		for _, column := range strings.Split(match[1], ",") {
+			v.Columns = append(v.Columns, strings.Trim(strings.TrimSpace(column), `"`))
+		}
+	}
+	return v, true
+}
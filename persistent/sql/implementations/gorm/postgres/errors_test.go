@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/neazossa/common-util-go/shared/shared"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestViolationColumns(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		err     *pgconn.PgError
+		is      func(err error) (Violation, bool)
+		columns []string
+	}{
+		{
+			name:    "unique single column",
+			err:     &pgconn.PgError{Code: CodeUniqueViolation, Detail: `Key (email)=(john@doe.com) already exists.`},
+			is:      IsUniqueViolation,
+			columns: []string{"email"},
+		},
+		{
+			name:    "unique composite key",
+			err:     &pgconn.PgError{Code: CodeUniqueViolation, Detail: `Key (user_id, role_id)=(1, 2) already exists.`},
+			is:      IsUniqueViolation,
+			columns: []string{"user_id", "role_id"},
+		},
+		{
+			name:    "quoted columns",
+			err:     &pgconn.PgError{Code: CodeUniqueViolation, Detail: `Key ("Email", "Tenant")=(a@b.co, 1) already exists.`},
+			is:      IsUniqueViolation,
+			columns: []string{"Email", "Tenant"},
+		},
+		{
+			name:    "value holding parenthesis",
+			err:     &pgconn.PgError{Code: CodeUniqueViolation, Detail: `Key (name)=(john (admin)) already exists.`},
+			is:      IsUniqueViolation,
+			columns: []string{"name"},
+		},
+		{
+			name:    "expression index",
+			err:     &pgconn.PgError{Code: CodeUniqueViolation, Detail: `Key (lower(email::text))=(john@doe.com) already exists.`},
+			is:      IsUniqueViolation,
+			columns: []string{"lower(email::text)"},
+		},
+		{
+			name:    "foreign key not present",
+			err:     &pgconn.PgError{Code: CodeForeignKeyViolation, Detail: `Key (user_id)=(1) is not present in table "users".`},
+			is:      IsForeignKeyViolation,
+			columns: []string{"user_id"},
+		},
+		{
+			name:    "foreign key still referenced",
+			err:     &pgconn.PgError{Code: CodeForeignKeyViolation, Detail: `Key (id)=(1) is still referenced from table "orders".`},
+			is:      IsForeignKeyViolation,
+			columns: []string{"id"},
+		},
+		{
+			name:    "not null column name preferred over detail",
+			err:     &pgconn.PgError{Code: CodeNotNullViolation, ColumnName: "email", Detail: `Failing row contains (1, null).`},
+			is:      IsNotNullViolation,
+			columns: []string{"email"},
+		},
+		{
+			name: "check without key",
+			err:  &pgconn.PgError{Code: CodeCheckViolation, Detail: `Failing row contains (1, -5).`},
+			is:   IsCheckViolation,
+		},
+		{
+			name: "empty detail",
+			err:  &pgconn.PgError{Code: CodeUniqueViolation},
+			is:   IsUniqueViolation,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v, ok := tc.is(fmt.Errorf("failed to create record: %w", tc.err))
+			if !ok {
+				t.Fatalf("violation not recognized through the wrapped error")
+			}
+			if !reflect.DeepEqual(v.Columns, tc.columns) {
+				t.Errorf("columns = %q, want %q", v.Columns, tc.columns)
+			}
+			if v.Code != tc.err.Code || v.Detail != tc.err.Detail {
+				t.Errorf("violation = %+v", v)
+			}
+		})
+	}
+
+	if _, ok := IsUniqueViolation(&pgconn.PgError{Code: CodeForeignKeyViolation}); ok {
+		t.Errorf("foreign key violation recognized as unique violation")
+	}
+	if _, ok := IsUniqueViolation(errors.New("duplicate key")); ok {
+		t.Errorf("plain error recognized as unique violation")
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		code   codes.Code
+		status int
+	}{
+		{nil, codes.OK, http.StatusOK},
+		{ErrRecordNotFound, codes.NotFound, http.StatusNotFound},
+		{&pgconn.PgError{Code: CodeUniqueViolation}, codes.AlreadyExists, http.StatusConflict},
+		{&pgconn.PgError{Code: CodeForeignKeyViolation}, codes.FailedPrecondition, http.StatusConflict},
+		{&pgconn.PgError{Code: CodeSerializationFailure}, codes.Aborted, http.StatusConflict},
+		{&pgconn.PgError{Code: CodeNotNullViolation}, codes.InvalidArgument, http.StatusBadRequest},
+		{&pgconn.PgError{Code: CodeCheckViolation}, codes.InvalidArgument, http.StatusBadRequest},
+		{&pgconn.PgError{Code: "42P01", Message: `relation "orders" does not exist`}, codes.Internal, http.StatusInternalServerError},
+		{errors.New("connection refused"), codes.Internal, http.StatusInternalServerError},
+	} {
+		grpcErr := GRPCError(tc.err)
+		if code := status.Code(grpcErr); code != tc.code {
+			t.Errorf("GRPCError(%v) code = %s, want %s", tc.err, code, tc.code)
+		}
+		if got := HTTPStatus(tc.err); got != tc.status {
+			t.Errorf("HTTPStatus(%v) = %d, want %d", tc.err, got, tc.status)
+		}
+		if got, _ := shared.GrpcToHttpStatusError(grpcErr); got != tc.status {
+			t.Errorf("GrpcToHttpStatusError(GRPCError(%v)) = %d, want %d", tc.err, got, tc.status)
+		}
+
+		// - the constraint and the rejected values stay on the server
+		if pgErr, ok := tc.err.(*pgconn.PgError); ok && pgErr.Message != "" && status.Convert(grpcErr).Message() == pgErr.Message {
+			t.Errorf("GRPCError(%v) leaked the database message", tc.err)
+		}
+	}
+}
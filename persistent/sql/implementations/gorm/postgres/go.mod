@@ -3,10 +3,14 @@ module github.com/neazossa/common-util-go/persistent/sql/implementations/gorm/po
 go 1.20
 
 require (
-	github.com/neazossa/common-util-go/logger/logger v1.0.0
-	github.com/neazossa/common-util-go/monitor/monitor v1.0.0
-	github.com/neazossa/common-util-go/persistent/sql/sql v1.0.2
+	github.com/DATA-DOG/go-sqlmock v1.5.0
+	github.com/jackc/pgconn v1.13.0
+	github.com/neazossa/common-util-go/logger/logger v1.1.0
+	github.com/neazossa/common-util-go/monitor/monitor v1.1.0
+	github.com/neazossa/common-util-go/persistent/sql/sql v1.1.0
+	github.com/neazossa/common-util-go/shared/shared v1.1.0
 	github.com/pkg/errors v0.9.1
+	google.golang.org/grpc v1.49.0
 	gorm.io/driver/postgres v1.3.10
 	gorm.io/gorm v1.23.10
 )
@@ -14,7 +18,6 @@ require (
 require (
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/jackc/chunkreader/v2 v2.0.1 // indirect
-	github.com/jackc/pgconn v1.13.0 // indirect
 	github.com/jackc/pgio v1.0.0 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgproto3/v2 v2.3.1 // indirect
@@ -29,11 +32,10 @@ require (
 	github.com/mattn/go-isatty v0.0.14 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.1 // indirect
-	golang.org/x/crypto v0.0.0-20220722155217-630584e8d5aa // indirect
+	golang.org/x/crypto v0.0.0-20220926161630-eccd6366d1be // indirect
 	golang.org/x/net v0.0.0-20211112202133-69e39bad7dc2 // indirect
 	golang.org/x/sys v0.0.0-20211103235746-7861aae1554b // indirect
 	golang.org/x/text v0.3.7 // indirect
 	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
-	google.golang.org/grpc v1.49.0 // indirect
 	google.golang.org/protobuf v1.27.1 // indirect
 )
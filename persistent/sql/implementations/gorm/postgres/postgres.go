@@ -2,8 +2,11 @@ package postgres
 
 import (
 	"context"
+	dbsql "database/sql"
 	"fmt"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/neazossa/common-util-go/persistent/sql/sql"
 	"gorm.io/gorm/clause"
@@ -16,6 +19,10 @@ import (
 	"gorm.io/gorm/schema"
 )
 
+var (
+	savepointSequence uint64
+)
+
 type (
 	SQL struct {
 		Logger         logger.Logger
@@ -91,7 +98,10 @@ func (s *SQL) OrWhere(query interface{}, args ...interface{}) sql.ORM {
 
 func (s *SQL) First(result interface{}) error {
 	defer s.doMonitor("GET FIRST", s.connection.DBName, getTableName(result))()
-	db := s.db.First(result)
+	db, cancel := s.statement()
+	defer cancel()
+
+	db = db.First(result)
 
 	if err := db.Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -111,7 +121,10 @@ func (s *SQL) All(result interface{}) (int64, error) {
 		s.Logger.Debug(db.Statement.Explain(db.Statement.SQL.String(), db.Statement.Vars...))
 	}
 
-	db := s.db.Find(result)
+	db, cancel := s.statement()
+	defer cancel()
+
+	db = db.Find(result)
 	if err := db.Error; err != nil {
 		return int64(0), s.captureError(errors.Wrap(err, "error fetch data from database"))
 	}
@@ -171,7 +184,10 @@ func (s *SQL) Create(data interface{}) error {
 		s.Logger.Debug(db.Statement.Explain(db.Statement.SQL.String(), db.Statement.Vars...))
 	}
 
-	return s.captureError(s.db.Create(data).Error)
+	db, cancel := s.statement()
+	defer cancel()
+
+	return s.captureError(db.Create(data).Error)
 }
 
 // Update : do update all given data
@@ -183,16 +199,21 @@ func (s *SQL) Update(data interface{}) error {
 		s.Logger.Debug(db.Statement.Explain(db.Statement.SQL.String(), db.Statement.Vars...))
 	}
 
-	return s.captureError(s.db.Save(data).Error)
+	db, cancel := s.statement()
+	defer cancel()
+
+	return s.captureError(db.Save(data).Error)
 }
 
 // Patch : only update non-zero fields
 // Use Update() to update all data
 func (s *SQL) Patch(data interface{}, fields ...string) error {
 	defer s.doMonitor("PATCH", s.connection.DBName, getTableName(data))()
-	db := s.db
+	db, cancel := s.statement()
+	defer cancel()
+
 	if s.DryRun {
-		dbDry := db.Session(&gorm.Session{DryRun: true})
+		dbDry := s.db.Session(&gorm.Session{DryRun: true})
 		if len(fields) > 0 {
 			dbDry = dbDry.Select(fields)
 		}
@@ -214,7 +235,10 @@ func (s *SQL) Delete(data interface{}, cond ...interface{}) error {
 		s.Logger.Debug(db.Statement.Explain(db.Statement.SQL.String(), db.Statement.Vars...))
 	}
 
-	return s.captureError(s.db.Delete(data, cond).Error)
+	db, cancel := s.statement()
+	defer cancel()
+
+	return s.captureError(db.Delete(data, cond).Error)
 }
 
 // Upsert : can upsert many values at once
@@ -244,7 +268,10 @@ func (s *SQL) Upsert(chunkSize int, data interface{}, onConflict sql.OnConflict)
 		s.Logger.Debug(db.Statement.Explain(db.Statement.SQL.String(), db.Statement.Vars...))
 	}
 
-	db := s.db.Clauses(clause.OnConflict{
+	db, cancel := s.statement()
+	defer cancel()
+
+	db = db.Clauses(clause.OnConflict{
 		Columns:   columns,
 		DoUpdates: clause.AssignmentColumns(onConflict.OnlyUpdate),
 	}).Create(data)
@@ -264,7 +291,10 @@ func (s *SQL) Exec(sql string, args ...interface{}) error {
 		s.Logger.Debug(db.Statement.Explain(db.Statement.SQL.String(), db.Statement.Vars...))
 	}
 
-	res := s.db.Exec(sql, args...)
+	db, cancel := s.statement()
+	defer cancel()
+
+	res := db.Exec(sql, args...)
 
 	if err := res.Error; err != nil {
 		return s.captureError(errors.Wrap(err, "failed to exec query"))
@@ -275,7 +305,10 @@ func (s *SQL) Exec(sql string, args ...interface{}) error {
 
 func (s *SQL) RawSql(rawSql string, object interface{}, args ...interface{}) error {
 	defer s.doMonitor("RAW SQL", s.connection.DBName, getTableName(object))()
-	err := s.db.Raw(rawSql, args).Scan(object).Error
+	db, cancel := s.statement()
+	defer cancel()
+
+	err := db.Raw(rawSql, args).Scan(object).Error
 	if err != nil {
 		return s.captureError(errors.Wrap(err, "failed to fetch raw query result"))
 	}
@@ -285,7 +318,10 @@ func (s *SQL) RawSql(rawSql string, object interface{}, args ...interface{}) err
 
 func (s *SQL) Scan(dest interface{}) error {
 	defer s.doMonitor("SCAN", s.connection.DBName, getTableName(dest))()
-	err := s.db.Scan(dest).Error
+	db, cancel := s.statement()
+	defer cancel()
+
+	err := db.Scan(dest).Error
 	if err != nil {
 		return s.captureError(errors.Wrap(err, "failed to scan result"))
 	}
@@ -357,7 +393,10 @@ func (s *SQL) Count() int64 {
 		count = int64(0)
 	)
 
-	s.db.Count(&count)
+	db, cancel := s.statement()
+	defer cancel()
+
+	db.Count(&count)
 	return count
 }
 
@@ -570,6 +609,117 @@ func (s *SQL) Rollback() error {
 	return nil
 }
 
+// Transaction run fn in a transaction, or in a savepoint when s is already a transaction, see sql.ORM
+func (s *SQL) Transaction(ctx context.Context, fn func(tx sql.ORM) error, option ...sql.TxOption) error {
+	var opt sql.TxOption
+	if len(option) > 0 {
+		opt = option[0]
+	}
+
+	orm := s.WithContext(ctx).(*SQL)
+	if orm.inTransaction() && opt != (sql.TxOption{}) {
+		return orm.captureError(ErrNestedTransactionOption)
+	}
+	if opt.RetryDelay <= 0 {
+		opt.RetryDelay = sql.DefaultRetryDelay
+	}
+
+	delay := opt.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := orm.transaction(fn, opt)
+		// - a serialization failure abort the outer transaction as well, only the outermost one is retried
+		if err == nil || orm.inTransaction() || attempt >= opt.MaxRetries || !IsSerializationFailure(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (s *SQL) transaction(fn func(tx sql.ORM) error, option sql.TxOption) (err error) {
+	ctx := s.ctx
+	if s.isMonitor {
+		tr := s.startMonitor("TRANSACTION", s.connection.DBName, "")
+		defer s.finishMonitor(tr)
+		ctx = tr.CreateNewTransactionContext(ctx)
+	}
+
+	var (
+		tx        *gorm.DB
+		savepoint string
+	)
+	if s.inTransaction() {
+		tx = s.db
+		savepoint = fmt.Sprintf("sp%d", atomic.AddUint64(&savepointSequence, 1))
+		if err := tx.SavePoint(savepoint).Error; err != nil {
+			return s.captureError(errors.Wrap(err, "savepoint failed"))
+		}
+	} else {
+		tx = s.db.Begin(txOptions(option))
+		if err := tx.Error; err != nil {
+			return s.captureError(errors.Wrap(err, "begin failed"))
+		}
+	}
+
+	// - rollback when fn panic too, the panic is not recovered. A failed commit already ended the transaction
+	var done, committed bool
+	defer func() {
+		if committed || (done && err == nil) {
+			return
+		}
+		if savepoint != "" {
+			tx.RollbackTo(savepoint)
+		} else {
+			tx.Rollback()
+		}
+	}()
+
+	err = fn(&SQL{
+		Logger:         s.Logger,
+		db:             tx.WithContext(ctx),
+		DryRun:         s.DryRun,
+		isMonitor:      s.isMonitor,
+		monitor:        s.monitor,
+		isCaptureError: s.isCaptureError,
+		ctx:            ctx,
+		connection:     s.connection,
+		requestId:      s.requestId,
+	})
+	done = true
+	if err != nil || savepoint != "" {
+		return err
+	}
+
+	committed = true
+	if err = tx.Commit().Error; err != nil {
+		return s.captureError(errors.Wrap(err, "commit failed"))
+	}
+	return nil
+}
+
+func (s *SQL) inTransaction() bool {
+	committer, ok := s.db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok && committer != nil
+}
+
+func txOptions(option sql.TxOption) *dbsql.TxOptions {
+	opts := &dbsql.TxOptions{ReadOnly: option.ReadOnly}
+	switch option.Isolation {
+	case sql.IsolationReadCommitted:
+		opts.Isolation = dbsql.LevelReadCommitted
+	case sql.IsolationRepeatableRead:
+		opts.Isolation = dbsql.LevelRepeatableRead
+	case sql.IsolationSerializable:
+		opts.Isolation = dbsql.LevelSerializable
+	}
+	return opts
+}
+
 func (s *SQL) Ping() error {
 	db, err := s.db.DB()
 	if err != nil {
@@ -595,12 +745,43 @@ func (s *SQL) Monitor(ctx context.Context, mntr monitor.Monitor, requestId strin
 		Logger:         s.Logger,
 		db:             s.db,
 		DryRun:         s.DryRun,
-		isMonitor:      true,
+		isMonitor:      mntr != nil,
 		monitor:        mntr,
 		ctx:            ctx,
+		connection:     s.connection,
 		requestId:      requestId,
-		isCaptureError: captureError,
+		isCaptureError: captureError && mntr != nil,
+	}
+}
+
+// WithContext set the context of the gorm session, the monitor spans and the captured errors are parented from it too
+func (s *SQL) WithContext(ctx context.Context) sql.ORM {
+	return &SQL{
+		Logger:         s.Logger,
+		db:             s.db.WithContext(ctx),
+		DryRun:         s.DryRun,
+		isMonitor:      s.isMonitor,
+		monitor:        s.monitor,
+		isCaptureError: s.isCaptureError,
+		ctx:            ctx,
+		connection:     s.connection,
+		requestId:      s.requestId,
+	}
+}
+
+// statement return the session running the next statement, bounded by the StatementTimeout of the connection.
+// The deadline is set per statement, a session kept by the caller would expire otherwise
+func (s *SQL) statement() (*gorm.DB, context.CancelFunc) {
+	if s.connection.StatementTimeout <= 0 {
+		return s.db, func() {}
+	}
+
+	ctx := s.db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	ctx, cancel := context.WithTimeout(ctx, s.connection.StatementTimeout)
+	return s.db.WithContext(ctx), cancel
 }
 
 func (s *SQL) doMonitor(action string, database, table string) func() {
@@ -615,6 +796,7 @@ func (s *SQL) doMonitor(action string, database, table string) func() {
 
 func (s *SQL) finishMonitor(transaction monitor.Transaction) {
 	transaction.Finish()
+	s.monitor.AddBreadcrumb(s.ctx, monitor.TransactionBreadcrumb(transaction, monitor.BreadcrumbQuery))
 }
 
 func (s *SQL) startMonitor(action string, database, table string) monitor.Transaction {
@@ -634,7 +816,7 @@ func (s *SQL) startMonitor(action string, database, table string) monitor.Transa
 
 func (s *SQL) captureError(err error) error {
 	if s.isCaptureError {
-		s.monitor.Capture(err)
+		s.monitor.WithContext(s.ctx).Capture(err)
 	}
 	return err
 }
@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgconn"
+	"github.com/neazossa/common-util-go/logger/logger/loggertest"
+	"github.com/neazossa/common-util-go/persistent/sql/sql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const (
+	querySavepoint   = `SAVEPOINT sp\d+`
+	queryRollbackTo  = `ROLLBACK TO SAVEPOINT sp\d+`
+	queryInsertOrder = `INSERT INTO orders`
+	queryUpdateStock = `UPDATE stocks`
+)
+
+var errDeclined = errors.New("payment declined")
+
+// newTestSQL return SQL running on sqlmock, every expectation must be met when the test end
+func newTestSQL(t *testing.T) (*SQL, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	return &SQL{Logger: loggertest.New(t), db: gdb, ctx: context.Background()}, mock
+}
+
+func TestTransactionCommit(t *testing.T) {
+	s, mock := newTestSQL(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(queryInsertOrder).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := s.Transaction(context.Background(), func(tx sql.ORM) error {
+		return tx.Exec("INSERT INTO orders (id) VALUES (1)")
+	})
+	if err != nil {
+		t.Errorf("transaction failed: %v", err)
+	}
+}
+
+func TestTransactionRollbackOnError(t *testing.T) {
+	s, mock := newTestSQL(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(queryInsertOrder).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectRollback()
+
+	err := s.Transaction(context.Background(), func(tx sql.ORM) error {
+		if err := tx.Exec("INSERT INTO orders (id) VALUES (1)"); err != nil {
+			return err
+		}
+		return errDeclined
+	})
+	if !errors.Is(err, errDeclined) {
+		t.Errorf("err = %v, want the error of fn", err)
+	}
+}
+
+func TestTransactionRollbackOnPanic(t *testing.T) {
+	s, mock := newTestSQL(t)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	defer func() {
+		if r := recover(); r != "nil map" {
+			t.Errorf("recovered %v, want the panic of fn to go through", r)
+		}
+	}()
+
+	s.Transaction(context.Background(), func(tx sql.ORM) error {
+		panic("nil map")
+	})
+}
+
+func TestNestedTransactionRollbackTo(t *testing.T) {
+	s, mock := newTestSQL(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(queryInsertOrder).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(querySavepoint).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(queryUpdateStock).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(queryRollbackTo).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := s.Transaction(context.Background(), func(tx sql.ORM) error {
+		if err := tx.Exec("INSERT INTO orders (id) VALUES (1)"); err != nil {
+			return err
+		}
+
+		// - the failed reservation is rolled back alone, the order is still committed
+		err := tx.Transaction(context.Background(), func(tx sql.ORM) error {
+			if err := tx.Exec("UPDATE stocks SET reserved = reserved + 1"); err != nil {
+				return err
+			}
+			return errDeclined
+		})
+		if !errors.Is(err, errDeclined) {
+			t.Errorf("nested err = %v, want the error of fn", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("transaction failed: %v", err)
+	}
+}
+
+func TestNestedTransactionOption(t *testing.T) {
+	s, mock := newTestSQL(t)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err := s.Transaction(context.Background(), func(tx sql.ORM) error {
+		return tx.Transaction(context.Background(), func(tx sql.ORM) error {
+			t.Error("nested fn called with an option")
+			return nil
+		}, sql.TxOption{Isolation: sql.IsolationSerializable})
+	})
+	if !errors.Is(err, ErrNestedTransactionOption) {
+		t.Errorf("err = %v, want ErrNestedTransactionOption", err)
+	}
+}
+
+func TestTransactionRetrySerializationFailure(t *testing.T) {
+	s, mock := newTestSQL(t)
+
+	serializationFailure := &pgconn.PgError{Code: CodeSerializationFailure, Message: "could not serialize access"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(queryUpdateStock).WillReturnError(serializationFailure)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(queryUpdateStock).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	attempts := 0
+	err := s.Transaction(context.Background(), func(tx sql.ORM) error {
+		attempts++
+		return tx.Exec("UPDATE stocks SET reserved = reserved + 1")
+	}, sql.TxOption{Isolation: sql.IsolationSerializable, MaxRetries: 1, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Errorf("transaction failed after retry: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("fn called %d times, want 2", attempts)
+	}
+}
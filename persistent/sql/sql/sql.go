@@ -2,12 +2,14 @@ package sql
 
 import (
 	"context"
+	"time"
 
 	"github.com/neazossa/common-util-go/monitor/monitor"
 )
 
 type (
-	JoinType string
+	JoinType       string
+	IsolationLevel string
 
 	Query struct {
 		Field    string
@@ -78,23 +80,43 @@ type (
 		Begin() ORM
 		Commit() error
 		Rollback() error
+		// - Transaction commit when fn succeed, rollback when it fail or panic. Called on the tx given to fn, it run in a
+		//   SAVEPOINT rolled back alone, the options of the outer transaction apply and giving any is an error
+		Transaction(ctx context.Context, fn func(tx ORM) error, option ...TxOption) error
 		Ping() error
 		Close() error
 		Error() error
 		RowsAffected() int64
 
 		Monitor(ctx context.Context, mntr monitor.Monitor, requestId string, captureError bool) ORM
+		// - WithContext run the statements with ctx, canceling ctx cancel the running statement, and parent the
+		//   monitor spans from it instead of the context given to Monitor
+		WithContext(ctx context.Context) ORM
 	}
 
 	Connection struct {
-		Host     string
-		Username string
-		Password string
-		DBName   string
-		Port     string
+		Host     string `config:",required"`
+		Username string `config:",required"`
+		Password string `config:",secret"`
+		DBName   string `config:",required"`
+		Port     string `default:"5432"`
 		SSLMode  string
 		Timezone string
 		SQLDebug bool
+		// - StatementTimeout bound every query and write, 0 disable it. A context with an earlier deadline win
+		StatementTimeout time.Duration
+	}
+
+	// TxOption configure Transaction
+	// - Isolation default to the isolation of the database, READ COMMITTED for postgres
+	// - MaxRetries run the whole transaction again when it fail with a serialization failure (SQLSTATE 40001),
+	//   fn must then be safe to call again
+	// - RetryDelay is doubled after each retry, default DefaultRetryDelay
+	TxOption struct {
+		Isolation  IsolationLevel
+		ReadOnly   bool
+		MaxRetries int
+		RetryDelay time.Duration
 	}
 )
 
@@ -107,6 +129,12 @@ const (
 	RIGHT_JOIN JoinType = "RIGHT JOIN"
 	CROSS_JOIN JoinType = "CROSS JOIN"
 
+	IsolationReadCommitted  IsolationLevel = "READ COMMITTED"
+	IsolationRepeatableRead IsolationLevel = "REPEATABLE READ"
+	IsolationSerializable   IsolationLevel = "SERIALIZABLE"
+
+	DefaultRetryDelay = 50 * time.Millisecond
+
 	JoinTemplate   = "%s %s %s"    // [join type] [foreign table name] [on operation]
 	JoinOnTemplate = "%s %s %s %s" // [ON/AND] [local] [operator] [foreign]
 )
@@ -0,0 +1,46 @@
+package kafkago
+
+import (
+	"github.com/segmentio/kafka-go"
+
+	"github.com/neazossa/common-util-go/monitor/monitor"
+)
+
+type (
+	headerCarrier struct {
+		headers *[]kafka.Header
+	}
+)
+
+// HeaderCarrier adapt the headers of a kafka message to monitor.Carrier, to inject or extract the trace context
+func HeaderCarrier(headers *[]kafka.Header) monitor.Carrier {
+	return headerCarrier{headers: headers}
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, header := range *c.headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Set replace the value of key, or append the header when key is missing
+func (c headerCarrier) Set(key, value string) {
+	for i, header := range *c.headers {
+		if header.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, header := range *c.headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
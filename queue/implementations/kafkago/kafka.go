@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/segmentio/kafka-go"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,6 +24,11 @@ type (
 		isCaptureError bool
 		requestId      string
 	}
+
+	// detachedContext keep the values of its parent, such as the trace, without its cancellation
+	detachedContext struct {
+		parent context.Context
+	}
 )
 
 func NewKafkaConnection(connection queue.Connection, logger logger.Logger) (queue.Kafka, error) {
@@ -42,17 +48,16 @@ func NewKafkaConnection(connection queue.Connection, logger logger.Logger) (queu
 }
 
 func (mq *Queue) WriteMessages(ctx context.Context, topic, groupId string, msg interface{}) error {
-	defer mq.doMonitor("WRITE", topic, groupId)()
+	tr := mq.startMonitor("WRITE", topic, groupId)
+	defer mq.finishMonitor(tr)
 
 	brokers := strings.Split(mq.connection.Host, ",")
-	producer := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:     brokers,
-		GroupID:     topic,
-		Topic:       groupId,
-		MinBytes:    10e3,
-		MaxBytes:    10e6,
-		StartOffset: kafka.LastOffset,
-	})
+	producer := &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Topic:    topic,
+		Balancer: &kafka.LeastBytes{},
+	}
+	defer producer.Close()
 
 	// parse data
 	dataParse, err := json.Marshal(msg)
@@ -60,8 +65,15 @@ func (mq *Queue) WriteMessages(ctx context.Context, topic, groupId string, msg i
 		return mq.captureError(err)
 	}
 
+	message := kafka.Message{Value: dataParse}
+
+	// - send the trace context so the consumer continue the same trace
+	if tr != nil {
+		mq.monitor.Inject(tr.CreateNewTransactionContext(ctx), HeaderCarrier(&message.Headers))
+	}
+
 	// publish message
-	if err := producer.CommitMessages(context.Background(), kafka.Message{Value: []byte(dataParse)}); err != nil {
+	if err := producer.WriteMessages(ctx, message); err != nil {
 		return mq.captureError(err)
 	}
 
@@ -74,44 +86,133 @@ func (mq *Queue) ReadMessages(ctx context.Context, topic, groupId string, handle
 	brokers := strings.Split(mq.connection.Host, ",")
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:     brokers,
-		GroupID:     topic,
-		Topic:       groupId,
+		GroupID:     groupId,
+		Topic:       topic,
 		MinBytes:    10e3,
 		MaxBytes:    10e6,
 		StartOffset: kafka.LastOffset,
 	})
 
+	// - every fetched message is logged, sample them to avoid flooding the log
+	sampled := logger.NewSampler(mq.Logger, logger.SamplerOption{})
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			mq.Logger.Error("failed to close reader: ", err)
+		}
+	}()
+
+	// - the message being handled is drained on shutdown, only the fetch is canceled
+	drainCtx := detachedContext{parent: ctx}
+
 	// consume
 	for {
 		message, errFetch := reader.FetchMessage(ctx)
 
 		if errFetch != nil {
+			// - ctx canceled on shutdown, the reader leave the group when closed
+			if ctx.Err() != nil {
+				return nil
+			}
 			mq.Logger.Error("failed to fetch messages: ", errFetch)
+			continue
 		}
-		mq.Logger.Info("success to fetch message", string(message.Value), message.Offset)
+		sampled.Info("success to fetch message", string(message.Value), message.Offset)
 
-		err := handler(ctx, message)
+		err := mq.handle(drainCtx, topic, groupId, message, handler)
 		// retrying
 		if err != nil && retry {
 			mq.Logger.Info("retrying message...")
-			if err := reader.CommitMessages(ctx, message); err != nil {
+			if err := reader.CommitMessages(drainCtx, message); err != nil {
 				mq.Logger.Error("failed to commit messages: ", err)
 			}
 		}
 	}
+}
 
-	return nil
+// handle run handler inside a transaction continuing the trace of the producer
+func (mq *Queue) handle(ctx context.Context, topic, groupId string, message kafka.Message, handler func(ctx context.Context, d kafka.Message) error) error {
+	if !mq.isMonitor {
+		return handler(ctx, message)
+	}
+
+	msgCtx := mq.monitor.Extract(ctx, HeaderCarrier(&message.Headers))
+	tr := mq.monitor.NewTransactionFromContext(msgCtx, monitor.Tick{
+		Operation:       "kafka",
+		TransactionName: "CONSUME " + topic,
+		Tags: []monitor.Tag{
+			{"requestId", mq.requestId},
+			{"action", "CONSUME"},
+			{"topic", topic},
+			{"groupId", groupId},
+			{"offset", strconv.FormatInt(message.Offset, 10)},
+		},
+	})
+	defer tr.Finish()
+
+	// - the events captured by the handler tell the message it was handling
+	handlerCtx := tr.CreateNewTransactionContext(msgCtx)
+	mq.monitor.AddBreadcrumb(handlerCtx, monitor.Breadcrumb{
+		Type:     monitor.BreadcrumbDefault,
+		Category: "kafka",
+		Message:  "CONSUME " + topic,
+		Data: map[string]interface{}{
+			"partition": message.Partition,
+			"offset":    message.Offset,
+			"key":       string(message.Key),
+		},
+		Level:     monitor.LevelInfo,
+		Timestamp: time.Now(),
+	})
+
+	// - captured in the consumer transaction, with the breadcrumb of the message
+	err := handler(handlerCtx, message)
+	if err != nil && mq.isCaptureError {
+		mq.monitor.WithContext(handlerCtx).Capture(err)
+	}
+	return err
+}
+
+// Ping dial the brokers in order until one answer with the cluster metadata
+func (mq *Queue) Ping(ctx context.Context) error {
+	var err error
+	for _, broker := range strings.Split(mq.connection.Host, ",") {
+		var conn *kafka.Conn
+		if conn, err = kafka.DialContext(ctx, "tcp", broker); err != nil {
+			continue
+		}
+
+		// - the dial honour ctx, the metadata request only the deadline of the connection
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		_, err = conn.Brokers()
+		conn.Close()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// Close close the connection dialed by NewKafkaConnection, the readers are closed when ReadMessages return
+func (mq *Queue) Close() error {
+	if mq.kafka == nil {
+		return nil
+	}
+	return mq.kafka.Close()
 }
 
 func (mq *Queue) Monitor(ctx context.Context, mntr monitor.Monitor, requestId string, captureError bool) queue.Kafka {
 	return &Queue{
 		Logger:         mq.Logger,
 		kafka:          mq.kafka,
-		isMonitor:      true,
+		connection:     mq.connection,
+		isMonitor:      mntr != nil,
 		monitor:        mntr,
 		ctx:            ctx,
 		requestId:      requestId,
-		isCaptureError: captureError,
+		isCaptureError: captureError && mntr != nil,
 	}
 }
 
@@ -126,10 +227,17 @@ func (mq *Queue) doMonitor(action, topic, groupID string) func() {
 }
 
 func (mq *Queue) finishMonitor(transaction monitor.Transaction) {
-	transaction.Finish()
+	if transaction != nil {
+		transaction.Finish()
+		mq.monitor.AddBreadcrumb(mq.ctx, monitor.TransactionBreadcrumb(transaction, monitor.BreadcrumbDefault))
+	}
 }
 
 func (mq *Queue) startMonitor(action, topic, groupID string) monitor.Transaction {
+	if !mq.isMonitor {
+		return nil
+	}
+
 	tags := []monitor.Tag{
 		{"requestId", mq.requestId},
 		{"action", action},
@@ -146,7 +254,23 @@ func (mq *Queue) startMonitor(action, topic, groupID string) monitor.Transaction
 
 func (mq *Queue) captureError(err error) error {
 	if mq.isCaptureError {
-		mq.monitor.Capture(err)
+		mq.monitor.WithContext(mq.ctx).Capture(err)
 	}
 	return err
 }
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
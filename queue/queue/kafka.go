@@ -10,14 +10,18 @@ import (
 type (
 	Kafka interface {
 		WriteMessages(ctx context.Context, topic, groupId string, msg interface{}) error
+		// - ReadMessages consume until ctx is canceled, the message being handled is finished before it return
 		ReadMessages(ctx context.Context, topic, groupId string, handler func(ctx context.Context, d kafka.Message) error, retry bool) error
+		// - Ping succeed when a broker answer with the cluster metadata
+		Ping(ctx context.Context) error
+		Close() error
 
 		Monitor(ctx context.Context, mntr monitor.Monitor, requestId string, captureError bool) Kafka
 	}
 
 	Connection struct {
-		Host          string
-		ReaderTimeout int // in seconds
-		WriterTimeout int // in seconds
+		Host          string `config:",required"`
+		ReaderTimeout int    // in seconds
+		WriterTimeout int    // in seconds
 	}
 )
@@ -0,0 +1,79 @@
+package requestctx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/neazossa/common-util-go/shared/shared"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	HeaderRequestID   = echo.HeaderXRequestID
+	MetadataRequestID = "x-request-id"
+)
+
+// EchoMiddleware store the request id from X-Request-ID header into the request context,
+// a new one is generated when the header is empty and sent back in the response
+func EchoMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := c.Request()
+
+		requestID := request.Header.Get(HeaderRequestID)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+
+		c.Response().Header().Set(HeaderRequestID, requestID)
+		c.SetRequest(request.WithContext(WithRequestID(request.Context(), requestID)))
+		return next(c)
+	}
+}
+
+// HTTPMiddleware is EchoMiddleware for net/http handlers
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+
+		w.Header().Set(HeaderRequestID, requestID)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// GRPCServerInterceptor store the request id from x-request-id metadata, or from requestId field of the request,
+// into the context given to the handler
+func GRPCServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(MetadataRequestID); len(values) > 0 {
+				requestID = values[0]
+			}
+		}
+
+		if requestID == "" {
+			requestID, _ = shared.GetRequestId(req)
+		}
+
+		if requestID != "" {
+			ctx = WithRequestID(ctx, requestID)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// GRPCClientInterceptor send the request id stored in context as x-request-id metadata
+func GRPCClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if requestID, ok := RequestIDFrom(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, MetadataRequestID, requestID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,75 @@
+package requestctx
+
+import (
+	"context"
+
+	"github.com/neazossa/common-util-go/logger/logger"
+	"github.com/neazossa/common-util-go/monitor/monitor"
+	"github.com/neazossa/common-util-go/shared/shared"
+)
+
+type (
+	User struct {
+		ID    string
+		Email string `log:"mask"`
+	}
+
+	requestIDKey   struct{}
+	transactionKey struct{}
+	userKey        struct{}
+)
+
+func init() {
+	logger.RegisterContextExtractor(fields)
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFrom return the request id stored by WithRequestID, false when there is none
+func RequestIDFrom(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+// RequestID prefer the request id stored in context, then the requestId field of req such as a grpc request
+func RequestID(ctx context.Context, req interface{}) string {
+	if requestID, ok := RequestIDFrom(ctx); ok {
+		return requestID
+	}
+
+	requestID, _ := shared.GetRequestId(req)
+	return requestID
+}
+
+// WithTransaction store the current transaction, used by monitor implementations to start child transactions
+func WithTransaction(ctx context.Context, transaction monitor.Transaction) context.Context {
+	return context.WithValue(ctx, transactionKey{}, transaction)
+}
+
+func TransactionFrom(ctx context.Context) (monitor.Transaction, bool) {
+	transaction, ok := ctx.Value(transactionKey{}).(monitor.Transaction)
+	return transaction, ok && transaction != nil
+}
+
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+func UserFrom(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userKey{}).(User)
+	return user, ok
+}
+
+// fields put request id and user id into every log entry written with logger.WithContext
+func fields(ctx context.Context) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if requestID, ok := RequestIDFrom(ctx); ok {
+		fields[logger.FieldRequestID] = requestID
+	}
+	if user, ok := UserFrom(ctx); ok && user.ID != "" {
+		fields[logger.FieldUserID] = user.ID
+	}
+	return fields
+}
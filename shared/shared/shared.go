@@ -22,7 +22,7 @@ type (
 		SentAttempt  int        `json:"sent_attempt,omitempty"`
 		Expiry       time.Time  `json:"expiry"`
 		Freeze       *time.Time `json:"freeze,omitempty"`
-		Token        string     `json:"token"`
+		Token        string     `json:"token" log:"mask"`
 	}
 
 	CommonRedisOTPPayloads []CommonRedisOTPPayload
@@ -108,6 +108,8 @@ func HttpToGrpcError(httpStatus int, err error) error {
 		return status.New(codes.PermissionDenied, err.Error()).Err()
 	case http.StatusNotFound:
 		return status.New(codes.NotFound, err.Error()).Err()
+	case http.StatusConflict:
+		return status.New(codes.AlreadyExists, err.Error()).Err()
 	case http.StatusTooManyRequests:
 		return status.New(codes.Unavailable, err.Error()).Err()
 	case http.StatusBadGateway:
@@ -123,6 +125,15 @@ func HttpToGrpcError(httpStatus int, err error) error {
 	}
 }
 
+// GrpcError return the grpc status error of code with the message of err, for the codes HttpToGrpcError cannot
+// give since they share an http status, such as FailedPrecondition and Aborted giving 409 like AlreadyExists
+func GrpcError(code codes.Code, err error) error {
+	if err == nil || code == codes.OK {
+		return nil
+	}
+	return status.New(code, err.Error()).Err()
+}
+
 func GrpcToHttpStatus(err error) int {
 	errStats, _ := status.FromError(err)
 
@@ -137,6 +148,8 @@ func GrpcToHttpStatus(err error) int {
 		return http.StatusForbidden
 	case codes.NotFound:
 		return http.StatusNotFound
+	case codes.AlreadyExists, codes.FailedPrecondition, codes.Aborted:
+		return http.StatusConflict
 	case codes.Unavailable:
 		return http.StatusServiceUnavailable
 	default:
@@ -158,6 +171,8 @@ func GrpcToHttpStatusError(err error) (int, error) {
 		return http.StatusForbidden, errors.New(errStats.Message())
 	case codes.NotFound:
 		return http.StatusNotFound, errors.New(errStats.Message())
+	case codes.AlreadyExists, codes.FailedPrecondition, codes.Aborted:
+		return http.StatusConflict, errors.New(errStats.Message())
 	case codes.Unavailable:
 		return http.StatusServiceUnavailable, errors.New(errStats.Message())
 	default:
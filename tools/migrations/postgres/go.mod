@@ -3,20 +3,14 @@ module github.com/neazossa/common-util-go/tools/migrations/postgres
 go 1.20
 
 require (
-	github.com/neazossa/common-util-go/persistent/sql/sql v1.0.1
+	github.com/neazossa/common-util-go/persistent/sql/sql v1.1.0
 	github.com/pkg/errors v0.9.1
 )
 
 require (
 	github.com/golang/protobuf v1.5.2 // indirect
-	github.com/labstack/echo/v4 v4.9.0 // indirect
-	github.com/labstack/gommon v0.3.1 // indirect
-	github.com/mattn/go-colorable v0.1.11 // indirect
-	github.com/mattn/go-isatty v0.0.14 // indirect
-	github.com/neazossa/common-util-go/monitor/monitor v1.0.0 // indirect
-	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	github.com/valyala/fasttemplate v1.2.1 // indirect
-	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5 // indirect
+	github.com/neazossa/common-util-go/logger/logger v1.1.0 // indirect
+	github.com/neazossa/common-util-go/monitor/monitor v1.1.0 // indirect
 	golang.org/x/net v0.0.0-20211015210444-4f30a5c0130f // indirect
 	golang.org/x/sys v0.0.0-20211103235746-7861aae1554b // indirect
 	golang.org/x/text v0.3.7 // indirect
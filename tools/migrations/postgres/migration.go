@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
@@ -110,23 +111,20 @@ func (m *migrator) Up() error {
 
 	for _, version := range keys {
 		fmt.Println("migrating : " + version)
-		db := m.Orm.Begin()
-		err := m.Scripts[version].Up(db)
-		if err != nil {
-			_ = db.Rollback()
-			fmt.Println("failed migrate : " + version)
-			return err
-		}
-
-		err = db.Create(&Migration{
-			Version:   version,
-			CreatedAt: time.Now(),
+		err := m.Orm.Transaction(context.Background(), func(db sql.ORM) error {
+			if err := m.Scripts[version].Up(db); err != nil {
+				return err
+			}
+
+			return db.Create(&Migration{
+				Version:   version,
+				CreatedAt: time.Now(),
+			})
 		})
 		if err != nil {
-			_ = db.Rollback()
+			fmt.Println("failed migrate : " + version)
 			return err
 		}
-		_ = db.Commit()
 		fmt.Println("migrated : " + version + "\n")
 	}
 
@@ -163,20 +161,17 @@ func (m *migrator) Down(downToVersion string) error {
 		}
 
 		fmt.Println("rolling back : " + version.Version)
-		db := m.Orm.Begin()
-		err := m.Scripts[version.Version].Down(db)
-		if err != nil {
-			_ = db.Rollback()
-			fmt.Println("failed rollback : " + version.Version)
-			return err
-		}
+		err := m.Orm.Transaction(context.Background(), func(db sql.ORM) error {
+			if err := m.Scripts[version.Version].Down(db); err != nil {
+				return err
+			}
 
-		err = db.Where("version = ?", version.Version).Delete(version)
+			return db.Where("version = ?", version.Version).Delete(version)
+		})
 		if err != nil {
-			_ = db.Rollback()
+			fmt.Println("failed rollback : " + version.Version)
 			return err
 		}
-		_ = db.Commit()
 		fmt.Println("rolled back : " + version.Version + "\n")
 	}
 
@@ -30,11 +30,11 @@ type (
 	}
 
 	Option struct {
-		Host       string
+		Host       string `config:",required"`
 		Port       string
-		BucketName string
-		AccessKey  string
-		SecretKey  string
+		BucketName string `config:",required"`
+		AccessKey  string `config:",required"`
+		SecretKey  string `config:",required,secret"`
 	}
 )
 
@@ -52,6 +52,7 @@ func (m *Minio) SetPutOption(opt interface{}) uploader.Uploader {
 		getOpt:         m.getOpt,
 		putOpt:         options,
 		rmvOpt:         m.rmvOpt,
+		isMonitor:      m.isMonitor,
 		monitor:        m.monitor,
 		context:        m.context,
 		isCaptureError: m.isCaptureError,
@@ -93,6 +94,14 @@ func (m *Minio) bucketCheck() error {
 	return nil
 }
 
+// Ping ask minio whether the bucket exist, a missing bucket is fine since Put create it
+func (m *Minio) Ping(ctx context.Context) error {
+	if _, err := m.client.BucketExists(ctx, m.option.BucketName); err != nil {
+		return errors.Wrap(err, "failed to reach minio")
+	}
+	return nil
+}
+
 func (m *Minio) SetGetOption(opt interface{}) uploader.Uploader {
 	options, ok := opt.(minio.GetObjectOptions)
 	if !ok {
@@ -107,7 +116,7 @@ func (m *Minio) SetGetOption(opt interface{}) uploader.Uploader {
 		getOpt:         options,
 		putOpt:         m.putOpt,
 		rmvOpt:         m.rmvOpt,
-		isMonitor:      true,
+		isMonitor:      m.isMonitor,
 		monitor:        m.monitor,
 		context:        m.context,
 		isCaptureError: m.isCaptureError,
@@ -153,7 +162,7 @@ func (m *Minio) SetRemoveOption(opt interface{}) uploader.Uploader {
 		getOpt:         m.getOpt,
 		putOpt:         m.putOpt,
 		rmvOpt:         options,
-		isMonitor:      true,
+		isMonitor:      m.isMonitor,
 		monitor:        m.monitor,
 		context:        m.context,
 		isCaptureError: m.isCaptureError,
@@ -203,10 +212,10 @@ func (m *Minio) Monitor(ctx context.Context, mntr monitor.Monitor, requestId str
 		getOpt:         m.getOpt,
 		putOpt:         m.putOpt,
 		rmvOpt:         m.rmvOpt,
-		isMonitor:      true,
+		isMonitor:      mntr != nil,
 		monitor:        mntr,
 		context:        ctx,
-		isCaptureError: captureError,
+		isCaptureError: captureError && mntr != nil,
 		requestId:      requestId,
 	}
 }
@@ -250,7 +259,7 @@ func (m *Minio) doMonitor(action string, keys ...string) func() {
 
 func (m *Minio) captureError(err error) error {
 	if m.isCaptureError {
-		m.monitor.Capture(err)
+		m.monitor.WithContext(m.context).Capture(err)
 	}
-	return m.captureError(err)
+	return err
 }
@@ -17,6 +17,9 @@ type (
 		SetRemoveOption(opt interface{}) Uploader
 		Remove(fileName string) error
 
+		// - Ping succeed when the storage answer, the bucket does not need to exist yet
+		Ping(ctx context.Context) error
+
 		Monitor(ctx context.Context, mntr monitor.Monitor, requestId string, captureError bool) Uploader
 	}
 )